@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"encoding/json"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+type BeatmapInfo struct {
+	MD5        string `json:"md5"`
+	ID         int64  `json:"id"`
+	SetID      int64  `json:"set_id"`
+	Artist     string `json:"artist"`
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty"`
+	Creator    string `json:"creator"`
+}
+
+type Judgement struct {
+	Time   int64   `json:"time"`
+	Object int64   `json:"object"`
+	Result string  `json:"result"`
+	Combo  uint    `json:"combo"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+
+	hitResult osu.HitResult
+}
+
+type Report struct {
+	Player  string      `json:"player"`
+	Beatmap BeatmapInfo `json:"beatmap"`
+	Mods    string      `json:"mods"`
+
+	Score        int64   `json:"score"`
+	Accuracy     float64 `json:"accuracy"`
+	Grade        string  `json:"grade"`
+	MaxCombo     uint    `json:"max_combo"`
+	PerfectCombo bool    `json:"perfect_combo"`
+	PP           float64 `json:"pp"`
+
+	Count300    uint `json:"count_300"`
+	CountGeki   uint `json:"count_geki"`
+	Count100    uint `json:"count_100"`
+	CountKatu   uint `json:"count_katu"`
+	Count50     uint `json:"count_50"`
+	CountMiss   uint `json:"count_miss"`
+	SliderBreak uint `json:"slider_breaks"`
+
+	Failed   bool  `json:"failed"`
+	FailTime int64 `json:"fail_time,omitempty"`
+
+	Judgements []Judgement `json:"judgements"`
+
+	LifeBar *LifeBarComparison `json:"life_bar,omitempty"`
+
+	hpStart int64
+	hp      []float64 // HP in every millisecond since hpStart
+}
+
+// AnalyzeReplay runs the replay set in settings.REPLAY through OsuRuleSet without drawing anything.
+// Beatmap has to have its objects parsed and mods applied beforehand.
+func AnalyzeReplay(beatMap *beatmap.BeatMap) *Report {
+	controller := dance.NewReplayController().(*dance.ReplayController)
+	controller.SetBeatMap(beatMap)
+	controller.InitCursors()
+
+	cursor := controller.GetCursors()[0]
+	ruleset := controller.GetRuleset()
+
+	report := &Report{
+		Player: cursor.Name,
+		Beatmap: BeatmapInfo{
+			MD5:        beatMap.MD5,
+			ID:         beatMap.ID,
+			SetID:      beatMap.SetID,
+			Artist:     beatMap.Artist,
+			Title:      beatMap.Name,
+			Difficulty: beatMap.Difficulty,
+			Creator:    beatMap.Creator,
+		},
+		Mods:       ruleset.GetPlayerDifficulty(cursor).GetModString(),
+		Judgements: make([]Judgement, 0),
+	}
+
+	var currentTime int64
+
+	ruleset.SetListener(func(c *graphics.Cursor, result osu.JudgementResult, score osu.Score) {
+		if c != cursor || result.HitResult == osu.PositionalMiss {
+			return
+		}
+
+		report.Judgements = append(report.Judgements, Judgement{
+			Time:   result.Time,
+			Object: result.Number,
+			Result: result.HitResult.String(),
+			Combo:  score.CurrentCombo,
+			X:      result.Position.X,
+			Y:      result.Position.Y,
+
+			hitResult: result.HitResult,
+		})
+	})
+
+	ruleset.SetFailListener(func(c *graphics.Cursor) {
+		if c != cursor || report.Failed {
+			return
+		}
+
+		report.Failed = true
+		report.FailTime = currentTime
+	})
+
+	startTime := min(-1000, beatMap.HitObjects[0].GetStartTime()-beatMap.Diff.Preempt)
+	endTime := beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime() + float64(beatMap.Diff.Hit50) + 1000
+
+	report.hpStart = int64(startTime)
+	report.hp = make([]float64, 0, int(endTime-startTime)+1)
+
+	for t := startTime; t <= endTime; t++ {
+		currentTime = int64(t)
+		controller.Update(t, 1)
+
+		report.hp = append(report.hp, ruleset.GetHP(cursor))
+	}
+
+	score := ruleset.GetScore(cursor)
+
+	report.Score = score.Score
+	report.Accuracy = score.Accuracy
+	report.Grade = score.Grade.String()
+	report.MaxCombo = score.Combo
+	report.PerfectCombo = score.PerfectCombo
+	report.PP = score.PP.Total
+	report.Count300 = score.Count300
+	report.CountGeki = score.CountGeki
+	report.Count100 = score.Count100
+	report.CountKatu = score.CountKatu
+	report.Count50 = score.Count50
+	report.CountMiss = score.CountMiss
+	report.SliderBreak = score.CountSB
+
+	if report.Failed {
+		log.Println("Player failed at:", report.FailTime)
+	}
+
+	if len(cursor.LifeBar) > 0 {
+		report.LifeBar = CompareLifeBar(report, cursor.LifeBar)
+	}
+
+	return report
+}
+
+// Save writes the report as indented JSON
+func (report *Report) Save(path string) error {
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// GetHP returns HP computed by danser at given time
+func (report *Report) GetHP(time int64) float64 {
+	if len(report.hp) == 0 {
+		return 0
+	}
+
+	return report.hp[max(0, min(time-report.hpStart, int64(len(report.hp)-1)))]
+}
+
+// GetDefaultPath returns report path next to the replay file
+func GetDefaultPath() string {
+	return settings.REPLAY[:len(settings.REPLAY)-len(filepath.Ext(settings.REPLAY))] + ".json"
+}
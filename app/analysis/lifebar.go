@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+// driftThreshold is the HP difference above which danser's life bar is considered to diverge from the recorded one
+const driftThreshold = 0.05
+
+type LifeBarSample struct {
+	Time     int64   `json:"time"`
+	Recorded float64 `json:"recorded"`
+	Computed float64 `json:"computed"`
+}
+
+type LifeBarComparison struct {
+	MaxDeviation     float64 `json:"max_deviation"`
+	MaxDeviationTime int64   `json:"max_deviation_time"`
+
+	// Time of the first sample deviating by more than driftThreshold, -1 if there's none
+	DriftStart int64 `json:"drift_start"`
+
+	RecordedFailed bool `json:"recorded_failed"` // recorded HP reached zero
+	ComputedFailed bool `json:"computed_failed"`
+
+	Samples []LifeBarSample `json:"samples"`
+}
+
+// CompareLifeBar compares HP computed by danser with life bar graph recorded in the replay.
+// Life bar points are expected to be in (time, hp) format with hp in 0-1 range.
+func CompareLifeBar(report *Report, lifeBar []vector.Vector2d) *LifeBarComparison {
+	comparison := &LifeBarComparison{
+		DriftStart:     -1,
+		ComputedFailed: report.Failed,
+		Samples:        make([]LifeBarSample, 0, len(lifeBar)),
+	}
+
+	for _, p := range lifeBar {
+		sample := LifeBarSample{
+			Time:     int64(p.X),
+			Recorded: p.Y,
+			Computed: report.GetHP(int64(p.X)),
+		}
+
+		comparison.Samples = append(comparison.Samples, sample)
+
+		if sample.Recorded <= 0 {
+			comparison.RecordedFailed = true
+		}
+
+		deviation := math.Abs(sample.Recorded - sample.Computed)
+
+		if deviation > comparison.MaxDeviation {
+			comparison.MaxDeviation = deviation
+			comparison.MaxDeviationTime = sample.Time
+		}
+
+		if deviation > driftThreshold && comparison.DriftStart < 0 {
+			comparison.DriftStart = sample.Time
+		}
+	}
+
+	return comparison
+}
+
+// Drifted returns true if danser's HP diverged from the recorded one
+func (comparison *LifeBarComparison) Drifted() bool {
+	return comparison.DriftStart >= 0
+}
@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/files"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type ObjectStrain struct {
+	Index int     `json:"index"`
+	Time  float64 `json:"time"`
+	Type  string  `json:"type"`
+
+	AimStrain        float64 `json:"aim_strain"`
+	SpeedStrain      float64 `json:"speed_strain"`
+	FlashlightStrain float64 `json:"flashlight_strain"`
+
+	// Star ratings of the map up to and including this object
+	Stars           float64 `json:"stars"`
+	StarsAim        float64 `json:"stars_aim"`
+	StarsSpeed      float64 `json:"stars_speed"`
+	StarsFlashlight float64 `json:"stars_flashlight"`
+
+	// Contributions of this object to final difficult strain counts
+	AimDifficultStrain   float64 `json:"aim_difficult_strain"`
+	SpeedDifficultStrain float64 `json:"speed_difficult_strain"`
+}
+
+// CalculateStrains calculates per-object difficulty data with pp version selected in settings.
+// Beatmap has to have its objects parsed and mods applied beforehand.
+func CalculateStrains(beatMap *beatmap.BeatMap) ([]ObjectStrain, error) {
+	version := performance.GetVersion(settings.Gameplay.PPVersion)
+
+	diffCalc := version.NewDifficultyCalculator()
+
+	strainCalc, ok := diffCalc.(api.IObjectStrainCalculator)
+	if !ok {
+		return nil, fmt.Errorf("pp version %s doesn't support per-object strains", version.Name)
+	}
+
+	diff := beatMap.Diff
+
+	diff.DiffCalcMode = true // To use lazer's stack offset for stable plays without having to put LZ mod
+
+	steps := diffCalc.CalculateStep(beatMap.HitObjects, diff)
+	strains := strainCalc.CalculateObjectStrains(beatMap.HitObjects, diff)
+
+	diff.DiffCalcMode = false
+
+	results := make([]ObjectStrain, 0, len(beatMap.HitObjects))
+
+	for i, o := range beatMap.HitObjects {
+		results = append(results, ObjectStrain{
+			Index:                i,
+			Time:                 o.GetStartTime(),
+			Type:                 getObjectType(o),
+			AimStrain:            strains.Aim[i],
+			SpeedStrain:          strains.Speed[i],
+			FlashlightStrain:     strains.Flashlight[i],
+			Stars:                steps[i].Total,
+			StarsAim:             steps[i].Aim,
+			StarsSpeed:           steps[i].Speed,
+			StarsFlashlight:      steps[i].Flashlight,
+			AimDifficultStrain:   strains.AimDifficultStrains[i],
+			SpeedDifficultStrain: strains.SpeedDifficultStrains[i],
+		})
+	}
+
+	return results, nil
+}
+
+// SaveStrains writes strains as JSON if path has .json extension, CSV otherwise
+func SaveStrains(strains []ObjectStrain, path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(strains, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, data, 0644)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	_ = writer.Write([]string{"index", "time", "type", "aim_strain", "speed_strain", "flashlight_strain", "stars", "stars_aim", "stars_speed", "stars_flashlight", "aim_difficult_strain", "speed_difficult_strain"})
+
+	for _, s := range strains {
+		_ = writer.Write([]string{
+			strconv.Itoa(s.Index),
+			strconv.FormatFloat(s.Time, 'f', -1, 64),
+			s.Type,
+			formatFloat(s.AimStrain),
+			formatFloat(s.SpeedStrain),
+			formatFloat(s.FlashlightStrain),
+			formatFloat(s.Stars),
+			formatFloat(s.StarsAim),
+			formatFloat(s.StarsSpeed),
+			formatFloat(s.StarsFlashlight),
+			formatFloat(s.AimDifficultStrain),
+			formatFloat(s.SpeedDifficultStrain),
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// GetDefaultStrainsPath returns CSV path in danser's strains directory
+func GetDefaultStrainsPath(beatMap *beatmap.BeatMap) string {
+	name := fmt.Sprintf("%s - %s [%s]", beatMap.Artist, beatMap.Name, beatMap.Difficulty)
+
+	if mods := beatMap.Diff.GetModString(); mods != "" {
+		name += " +" + mods
+	}
+
+	return filepath.Join(env.DataDir(), "strains", files.FixName(name)+".csv")
+}
+
+func getObjectType(o objects.IHitObject) string {
+	switch o.(type) {
+	case *objects.Slider:
+		return "slider"
+	case *objects.Spinner:
+		return "spinner"
+	default:
+		return "circle"
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
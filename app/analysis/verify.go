@@ -0,0 +1,173 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/rplpa"
+	"os"
+	"path/filepath"
+)
+
+// boundaryTolerance is a distance in ms from hit window's edge below which a hit may be judged differently by osu!
+const boundaryTolerance = 2
+
+type Mismatch struct {
+	Field    string `json:"field"`
+	Recorded int64  `json:"recorded"`
+	Computed int64  `json:"computed"`
+}
+
+type SuspiciousObject struct {
+	Object int64  `json:"object"`
+	Time   int64  `json:"time"`
+	Result string `json:"result"`
+	Reason string `json:"reason"`
+}
+
+type Verification struct {
+	Player     string             `json:"player"`
+	Beatmap    BeatmapInfo        `json:"beatmap"`
+	Mods       string             `json:"mods"`
+	Matches    bool               `json:"matches"`
+	Mismatches []Mismatch         `json:"mismatches"`
+	Suspicious []SuspiciousObject `json:"suspicious"`
+
+	LifeBar *LifeBarComparison `json:"life_bar,omitempty"`
+}
+
+// VerifyReplay compares results computed by AnalyzeReplay with values recorded in the replay.
+// If there are mismatches, objects where judgements could have diverged are listed.
+func VerifyReplay(beatMap *beatmap.BeatMap, replay *rplpa.Replay, report *Report) *Verification {
+	verification := &Verification{
+		Player:     report.Player,
+		Beatmap:    report.Beatmap,
+		Mods:       report.Mods,
+		Mismatches: make([]Mismatch, 0),
+		Suspicious: make([]SuspiciousObject, 0),
+		LifeBar:    report.LifeBar,
+	}
+
+	compare := func(field string, recorded, computed int64) {
+		if recorded != computed {
+			verification.Mismatches = append(verification.Mismatches, Mismatch{
+				Field:    field,
+				Recorded: recorded,
+				Computed: computed,
+			})
+		}
+	}
+
+	compare("count_300", int64(replay.Count300), int64(report.Count300))
+	compare("count_geki", int64(replay.CountGeki), int64(report.CountGeki))
+	compare("count_100", int64(replay.Count100), int64(report.Count100))
+	compare("count_katu", int64(replay.CountKatu), int64(report.CountKatu))
+	compare("count_50", int64(replay.Count50), int64(report.Count50))
+	compare("count_miss", int64(replay.CountMiss), int64(report.CountMiss))
+	compare("max_combo", int64(replay.MaxCombo), int64(report.MaxCombo))
+
+	// Lazer replays store standardised score, danser may be set to show classic one
+	if replay.OsuVersion < 30000000 {
+		compare("score", int64(replay.Score), report.Score)
+	}
+
+	verification.Matches = len(verification.Mismatches) == 0
+
+	if !verification.Matches {
+		verification.Suspicious = findSuspicious(beatMap, report)
+	}
+
+	return verification
+}
+
+// findSuspicious looks for judgements that are sensitive to differences between danser and osu!
+func findSuspicious(beatMap *beatmap.BeatMap, report *Report) (suspicious []SuspiciousObject) {
+	suspicious = make([]SuspiciousObject, 0)
+
+	diff := beatMap.Diff
+
+	windows := []struct {
+		window int64
+		name   string
+	}{
+		{diff.Hit300, "300"},
+		{diff.Hit100, "100"},
+		{diff.Hit50, "50"},
+	}
+
+	for _, j := range report.Judgements {
+		if j.Object < 0 || j.Object >= int64(len(beatMap.HitObjects)) {
+			continue
+		}
+
+		obj := beatMap.HitObjects[j.Object]
+
+		add := func(reason string) {
+			suspicious = append(suspicious, SuspiciousObject{
+				Object: j.Object,
+				Time:   j.Time,
+				Result: j.Result,
+				Reason: reason,
+			})
+		}
+
+		_, isCircle := obj.(*objects.Circle)
+
+		base := j.hitResult & osu.BaseHitsM
+
+		switch {
+		case isCircle && base&osu.BaseHits > 0:
+			hitError := j.Time - int64(obj.GetStartTime())
+			if hitError < 0 {
+				hitError = -hitError
+			}
+
+			for _, w := range windows {
+				if d := hitError - w.window; d >= -boundaryTolerance && d <= boundaryTolerance {
+					add(fmt.Sprintf("hit error of %dms is on the edge of %s hit window (%dms)", hitError, w.name, w.window))
+					break
+				}
+			}
+		case base == osu.Miss:
+			if j.Object > 0 {
+				prev := beatMap.HitObjects[j.Object-1]
+
+				if prev.GetEndTime() >= obj.GetStartTime() {
+					add("missed during previous object (2B)")
+					break
+				}
+
+				if prev.GetStartTime()+float64(diff.Hit50) >= obj.GetStartTime()-float64(diff.Hit50) {
+					add("missed while hit windows overlap with previous object (possible notelock)")
+					break
+				}
+			}
+
+			if _, isSlider := obj.(*objects.Slider); isSlider {
+				add("missed slider")
+			}
+		case j.hitResult&osu.SliderMiss > 0:
+			add("slider tick, repeat or end missed")
+		}
+	}
+
+	return
+}
+
+// Save writes the verification as indented JSON
+func (verification *Verification) Save(path string) error {
+	data, err := json.MarshalIndent(verification, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"github.com/wieku/danser-go/app/analysis"
+	"github.com/wieku/danser-go/app/beatmap"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+func runAnalysis(beatMap *beatmap.BeatMap) {
+	log.Println("Analyzing replay...")
+
+	report := analysis.AnalyzeReplay(beatMap)
+
+	path := analysis.GetDefaultPath()
+	if output != "" {
+		path = strings.TrimSuffix(output, filepath.Ext(output)) + ".json"
+	}
+
+	if err := report.Save(path); err != nil {
+		panic("Failed to save the report: " + err.Error())
+	}
+
+	log.Println("Report saved to:", path)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/wieku/danser-go/app/audio"
 	"github.com/wieku/danser-go/app/beatmap"
 	difficulty2 "github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/query"
 	camera2 "github.com/wieku/danser-go/app/bmath/camera"
 	"github.com/wieku/danser-go/app/database"
 	"github.com/wieku/danser-go/app/discord"
@@ -71,6 +72,11 @@ var output string
 
 var recordMode bool
 var screenshotMode bool
+var analyzeMode bool
+var exportMode bool
+var ppCompareMode bool
+var strainsMode bool
+var verifyMode bool
 var screenshotTime float64
 
 var preciseProgress bool
@@ -90,6 +96,14 @@ func run() {
 
 		md5 := flag.String("md5", "", "Specify the beatmap md5 hash. Overrides other beatmap search flags")
 
+		osuFile := flag.String("file", "", "Specify the path to .osu file, it doesn't have to be imported. Overrides other beatmap search flags")
+
+		queryStr := flag.String("query", "", "Select the beatmap using a query, e.g. \"stars>6.5 bpm<=200 creator=Sotarks length>120s mode=0 sort=-stars pick=2\". Overrides artist/title/difficulty/creator flags")
+
+		list := flag.Bool("list", false, "Print all beatmaps matching -query and exit")
+
+		collection := flag.String("collection", "", "Restrict the beatmap search to osu! collection with the given name. If no other beatmap search flags are specified, every beatmap in the collection is run one after another")
+
 		artist := flag.String("artist", "", artistDesc)
 		flag.StringVar(artist, "a", "", artistDesc+shorthand)
 
@@ -127,6 +141,16 @@ func run() {
 		out := flag.String("out", "", "If -ss flag is used, sets the name of screenshot, extension is PNG. If not, it overrides -record flag, specifies the name of recorded video file, extension is managed by settings")
 		ss := flag.Float64("ss", math.NaN(), "Screenshot mode. Snap single frame from danser at given time in seconds. Specify the name of file by -out, resolution is managed by Recording settings")
 
+		analyze := flag.Bool("analyze", false, "Runs the replay given by -replay through the ruleset without creating a window and saves a JSON report. Specify the name of file by -out, by default it's saved next to the replay file")
+
+		verify := flag.Bool("verify", false, "Runs the replay given by -replay through the ruleset without creating a window and compares the results with values recorded in the replay. Saves a JSON report next to the replay file or to -out")
+
+		exportOsr := flag.Bool("exportosr", false, "Runs cursordance without creating a window and saves the path of each cursor as an .osr replay to danser's replays directory")
+
+		ppCompare := flag.Bool("ppcompare", false, "Calculates star rating and pp of the map with every bundled pp version and prints them as a table. Uses the play given by -replay if specified, SS otherwise. Specify -out to also save the results as JSON")
+
+		strains := flag.Bool("strains", false, "Calculates strains and star rating of every hit object and saves them as CSV. Specify the name of file by -out, .json extension saves JSON instead")
+
 		mods := flag.String("mods", "", "Specify beatmap/play mods")
 		mods2 := flag.String("mods2", "", "Specify beatmap/play mods, lazer style")
 
@@ -171,7 +195,7 @@ func run() {
 
 		if *out != "" {
 			output = *out
-			if math.IsNaN(*ss) {
+			if math.IsNaN(*ss) && !*analyze && !*verify && !*ppCompare && !*strains && !*list {
 				*record = true
 			}
 		}
@@ -179,6 +203,11 @@ func run() {
 		recordMode = *record
 		screenshotMode = !math.IsNaN(*ss)
 		screenshotTime = *ss
+		analyzeMode = *analyze
+		exportMode = *exportOsr
+		ppCompareMode = *ppCompare
+		strainsMode = *strains
+		verifyMode = *verify
 
 		if *record && *play {
 			panic("Incompatible flags selected: -record, -play")
@@ -192,10 +221,40 @@ func run() {
 			panic("Incompatible flags selected: -ss, -play")
 		} else if screenshotMode && recordMode {
 			panic("Incompatible flags selected: -ss, -record")
+		} else if analyzeMode && (recordMode || screenshotMode || *play || *knockout) {
+			panic("Incompatible flags selected: -analyze can only be used with -replay")
+		} else if analyzeMode && *replay == "" {
+			panic("-analyze requires -replay to be specified")
+		} else if verifyMode && (recordMode || screenshotMode || analyzeMode || *play || *knockout) {
+			panic("Incompatible flags selected: -verify can only be used with -replay")
+		} else if verifyMode && *replay == "" {
+			panic("-verify requires -replay to be specified")
+		} else if exportMode && (recordMode || screenshotMode || analyzeMode || verifyMode || *play || *knockout || *replay != "") {
+			panic("Incompatible flags selected: -exportosr can only be used in cursordance mode")
+		} else if ppCompareMode && (recordMode || screenshotMode || analyzeMode || verifyMode || exportMode || *play || *knockout) {
+			panic("Incompatible flags selected: -ppcompare can't be combined with other modes")
+		} else if strainsMode && (recordMode || screenshotMode || analyzeMode || verifyMode || exportMode || ppCompareMode || *play || *knockout || *replay != "") {
+			panic("Incompatible flags selected: -strains can't be combined with other modes")
+		} else if *collection != "" && (*replay != "" || *osuFile != "") {
+			panic("Incompatible flags selected: -collection can't be used with -replay or -file")
+		} else if *queryStr != "" && (*replay != "" || *osuFile != "") {
+			panic("Incompatible flags selected: -query can't be used with -replay or -file")
+		} else if *list && *queryStr == "" {
+			panic("-list requires -query to be specified")
+		}
+
+		var beatmapQuery *query.Query
+
+		if *queryStr != "" {
+			var err error
+			if beatmapQuery, err = query.Parse(*queryStr); err != nil {
+				panic(fmt.Sprintf("Failed to parse query: %s", err))
+			}
 		}
 
 		modsParsed := difficulty2.ParseMods(*mods)
 		var modsNew []rplpa.ModInfo = nil
+		var rulesetReplay *rplpa.Replay = nil
 
 		if *replay != "" {
 			bytes, err := ioutil.ReadFile(*replay)
@@ -208,8 +267,16 @@ func run() {
 				panic(err)
 			}
 
+			if rp.PlayMode != 0 && rp.PlayMode != 1 && rp.PlayMode != 3 {
+				panic("Modes other than osu!standard, osu!taiko and osu!mania are not supported")
+			}
+
 			if rp.PlayMode != 0 {
-				panic("Modes other than osu!standard are not supported")
+				if analyzeMode || verifyMode {
+					panic("Incompatible flags selected: -analyze and -verify support only osu!standard replays")
+				}
+
+				rulesetReplay = rp
 			}
 
 			if rp.ReplayData == nil || len(rp.ReplayData) < 2 {
@@ -262,7 +329,7 @@ func run() {
 
 		closeAfterSettingsLoad := false
 
-		if (*md5+*artist+*title+*difficulty+*creator) == "" && *id < 0 {
+		if (*md5+*artist+*title+*difficulty+*creator+*collection+*queryStr) == "" && *id < 0 {
 			log.Println("No beatmap specified, closing...")
 			closeAfterSettingsLoad = true
 		}
@@ -279,6 +346,7 @@ func run() {
 		settings.START = *start
 		settings.END = *end
 		settings.RECORD = recordMode || screenshotMode
+		settings.HEADLESS = analyzeMode || verifyMode || exportMode || ppCompareMode || strainsMode
 		settings.LOCALOFFSET = *offset
 
 		if *settingsVersion == "credentials" || *settingsVersion == "launcher" {
@@ -300,13 +368,35 @@ func run() {
 
 		player = nil
 		var beatMap *beatmap.BeatMap = nil
+		var collectionMaps []*beatmap.BeatMap
 
-		if !closeAfterSettingsLoad {
+		if !closeAfterSettingsLoad && *osuFile != "" {
+			bMap, err := beatmap.LoadBeatMapFile(*osuFile)
+			if err != nil {
+				log.Println("Failed to load beatmap file:", err)
+				closeAfterSettingsLoad = true
+			} else {
+				beatMap = bMap
+			}
+		} else if !closeAfterSettingsLoad {
 			err := database.Init()
 			if err != nil {
 				log.Println("Failed to initialize database:", err)
 			} else {
-				beatmaps := database.LoadBeatmaps(*noDbCheck, nil)
+				modes := []int64{0}
+				if rulesetReplay != nil && rulesetReplay.PlayMode == 1 {
+					modes = []int64{0, 1} // osu!taiko can play converted osu!standard beatmaps
+				} else if rulesetReplay != nil {
+					modes = []int64{int64(rulesetReplay.PlayMode)}
+				} else if *list {
+					modes = []int64{0, 1, 2, 3}
+				}
+
+				beatmaps := database.LoadBeatmapsMode(*noDbCheck, nil, modes...)
+
+				if *collection != "" {
+					beatmaps = filterByCollection(beatmaps, *collection)
+				}
 
 				if *id > -1 {
 					for _, b := range beatmaps {
@@ -324,6 +414,26 @@ func run() {
 							break
 						}
 					}
+				} else if beatmapQuery != nil {
+					results := filterByQuery(beatmaps, beatmapQuery)
+
+					log.Println(fmt.Sprintf("Query matched %d beatmaps", len(results)))
+
+					if *list {
+						printBeatmapList(results)
+
+						database.Close()
+
+						return
+					}
+
+					beatMap = beatmapQuery.Pick(results)
+				} else if *collection != "" && (*artist+*title+*difficulty+*creator) == "" {
+					if len(beatmaps) == 1 {
+						beatMap = beatmaps[0]
+					} else if len(beatmaps) > 1 {
+						collectionMaps = beatmaps
+					}
 				} else {
 					for _, b := range beatmaps {
 						if (*artist == "" || strings.EqualFold(*artist, b.Artist)) &&
@@ -352,10 +462,18 @@ func run() {
 				}
 			}
 
+			if collectionMaps != nil {
+				database.Close()
+
+				runCollection(collectionMaps)
+
+				return
+			}
+
 			if beatMap == nil {
 				log.Println("Beatmap not found, closing...")
 				closeAfterSettingsLoad = true
-			} else {
+			} else if !settings.HEADLESS { // analysis doesn't count as playing the map
 				beatMap.UpdatePlayStats()
 				database.UpdatePlayStats(beatMap)
 			}
@@ -363,6 +481,40 @@ func run() {
 			database.Close()
 		}
 
+		if settings.HEADLESS {
+			if newSettings {
+				settings.JsonPatch = *sPatch
+				settings.LoadPatch()
+			}
+
+			if closeAfterSettingsLoad {
+				os.Exit(0)
+			}
+
+			if modsNew != nil {
+				beatMap.Diff.SetMods2(modsNew)
+			} else {
+				beatMap.Diff.SetMods(modsParsed)
+			}
+
+			beatmap.ParseTimingPointsAndPauses(beatMap)
+			beatmap.ParseObjects(beatMap, false, false)
+
+			if analyzeMode {
+				runAnalysis(beatMap)
+			} else if verifyMode {
+				runVerification(beatMap)
+			} else if ppCompareMode {
+				runPPCompare(beatMap)
+			} else if strainsMode {
+				runStrains(beatMap)
+			} else {
+				runExport(beatMap)
+			}
+
+			return
+		}
+
 		assets.Init(build.Stream == "Dev")
 
 		if !closeAfterSettingsLoad {
@@ -599,13 +751,25 @@ func run() {
 		}
 
 		beatmap.ParseTimingPointsAndPauses(beatMap)
-		beatmap.ParseObjects(beatMap, false, true)
-		beatMap.LoadCustomSamples()
-		player = states.NewPlayer(beatMap)
+
+		if rulesetReplay != nil && rulesetReplay.PlayMode == 1 {
+			beatmap.ParseObjects(beatMap, false, false)
+			player = states.NewTaikoPlayer(beatMap, rulesetReplay)
+		} else if rulesetReplay != nil {
+			player = states.NewManiaPlayer(beatMap, rulesetReplay)
+		} else {
+			beatmap.ParseObjects(beatMap, false, true)
+			beatMap.LoadCustomSamples()
+			player = states.NewPlayer(beatMap)
+		}
 
 		limiter = frame.NewLimiter(int(settings.Graphics.FPSCap))
 	})
 
+	if settings.HEADLESS {
+		return
+	}
+
 	if recordMode {
 		mainLoopRecord()
 	} else if screenshotMode {
@@ -643,7 +807,7 @@ func mainLoopRecord() {
 	deltaSumF := fpsDelta
 	deltaSumA := 0.0
 
-	p, _ := player.(*states.Player)
+	p, _ := player.(states.PlaybackState)
 
 	lastCount := int64(0)
 	lastRealTime := qpc.GetMilliTimeF()
@@ -680,12 +844,12 @@ func mainLoopRecord() {
 				count++
 
 				timeOffset := p.GetTimeOffset()
-				progress = int(math.Round(timeOffset / p.RunningTime * 100))
+				progress = int(math.Round(timeOffset / p.GetRunningTime() * 100))
 
 				if (preciseProgress || progress%5 == 0) && lastProgress != progress {
 					speed := float64(count-lastCount) * (1000 / fps) / (qpc.GetMilliTimeF() - lastRealTime)
 
-					eta := int((p.RunningTime - timeOffset) / 1000 / speed)
+					eta := int((p.GetRunningTime() - timeOffset) / 1000 / speed)
 
 					etaText := util.FormatSeconds(eta)
 
@@ -720,7 +884,7 @@ func mainLoopSS() {
 		fbo = buffer.NewFrameMultisampleScreen(w, h, false, 0)
 	})
 
-	p, _ := player.(*states.Player)
+	p, _ := player.(states.PlaybackState)
 
 	for !p.Update(1) {
 		if p.GetTime() >= screenshotTime*1000 {
@@ -909,6 +1073,16 @@ func Run() {
 
 	platform.DisableQuickEdit()
 
+	if len(os.Args) > 1 && os.Args[1] == "db" {
+		runDatabaseCommand(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "human-model" {
+		runHumanModelCommand(os.Args[2:])
+		return
+	}
+
 	goroutines.RunMain(run)
 }
 
@@ -0,0 +1,68 @@
+package beatmap
+
+import "strings"
+
+// AssetStatus holds problems with files referenced by the beatmap, found during import
+type AssetStatus int
+
+// AssetsUnchecked means the beatmap was imported before asset checks were introduced
+const AssetsUnchecked = AssetStatus(-1)
+
+const (
+	AudioMissing = AssetStatus(1 << iota)
+	AudioUndecodable
+	AudioUnverified // Audio exists, but BASS wasn't initialized during import so it wasn't decoded
+	BackgroundMissing
+	VideoMissing
+)
+
+var assetProblemNames = []struct {
+	flag AssetStatus
+	name string
+}{
+	{AudioMissing, "audio missing"},
+	{AudioUndecodable, "audio undecodable"},
+	{AudioUnverified, "audio not verified"},
+	{BackgroundMissing, "background missing"},
+	{VideoMissing, "video missing"},
+}
+
+func (s AssetStatus) Has(flag AssetStatus) bool {
+	return s != AssetsUnchecked && s&flag > 0
+}
+
+// Verified returns true if the beatmap was checked during import with BASS initialized, so its audio was decoded.
+// Broken can't be trusted for beatmaps that aren't verified.
+func (s AssetStatus) Verified() bool {
+	return s != AssetsUnchecked && !s.Has(AudioUnverified)
+}
+
+// Broken returns true if the beatmap can't be played with its audio
+func (s AssetStatus) Broken() bool {
+	return s.Has(AudioMissing) || s.Has(AudioUndecodable)
+}
+
+// Problems returns descriptions of all found problems
+func (s AssetStatus) Problems() []string {
+	problems := make([]string, 0, len(assetProblemNames))
+
+	for _, p := range assetProblemNames {
+		if s.Has(p.flag) {
+			problems = append(problems, p.name)
+		}
+	}
+
+	return problems
+}
+
+func (s AssetStatus) String() string {
+	if s == AssetsUnchecked {
+		return "unchecked"
+	}
+
+	if problems := s.Problems(); len(problems) > 0 {
+		return strings.Join(problems, ", ")
+	}
+
+	return "ok"
+}
@@ -36,6 +36,7 @@ type BeatMap struct {
 	File  string
 	Audio string
 	Bg    string
+	Video string
 	MD5   string
 
 	SetID int64
@@ -65,6 +66,8 @@ type BeatMap struct {
 
 	LocalOffset int
 
+	Assets AssetStatus
+
 	pathCache *files.FileMap
 
 	stackCalcCache map[int64]bool
@@ -78,6 +81,7 @@ func NewBeatMap() *BeatMap {
 		Stars:          -1,
 		MinBPM:         math.Inf(0),
 		MaxBPM:         0,
+		Assets:         AssetsUnchecked,
 		stackCalcCache: make(map[int64]bool),
 	}
 
@@ -328,6 +328,15 @@ func ParseMods(mods string) (m Modifier) {
 	return
 }
 
+// Legacy returns mods that can be stored in osu!stable's mod bitfield
+func (mods Modifier) Legacy() Modifier {
+	if mods.Active(Daycore) {
+		mods |= HalfTime
+	}
+
+	return mods & (LastMod - 1)
+}
+
 func (mods Modifier) Active(mod Modifier) bool {
 	return mods&mod > 0
 }
@@ -63,6 +63,10 @@ func NewCircle(data []string) *Circle {
 	return circle
 }
 
+func (circle *Circle) GetSample() int {
+	return circle.sample
+}
+
 func DummyCircle(pos vector.Vector2f, time float64) *Circle {
 	return DummyCircleInherit(pos, time, false, false, false)
 }
@@ -55,6 +55,7 @@ type Slider struct {
 	*HitObject
 
 	multiCurve  *curves.MultiCurve
+	curveDefs   []curves.CurveDef
 	scorePath   []PathLine
 	Timings     *Timings
 	TPoint      TimingPoint
@@ -251,6 +252,8 @@ func (slider *Slider) parseCurve(curveData string) *curves.MultiCurve {
 		}
 	}
 
+	slider.curveDefs = defs
+
 	return curves.NewMultiCurveT(defs, slider.pixelLength)
 }
 
@@ -273,6 +276,24 @@ func (slider *Slider) GetLength() float32 {
 	return slider.multiCurve.GetLength()
 }
 
+// GetCurveDefs returns curve segments as they were defined in .osu file, first point of first segment is slider's start position
+func (slider *Slider) GetCurveDefs() []curves.CurveDef {
+	return slider.curveDefs
+}
+
+func (slider *Slider) GetPixelLength() float64 {
+	return slider.pixelLength
+}
+
+func (slider *Slider) GetBaseSample() int {
+	return slider.baseSample
+}
+
+// GetEdgeSamples returns hitsounds, sample sets and addition sets of slider's head, repeats and tail
+func (slider *Slider) GetEdgeSamples() (samples, sampleSets, additionSets []int) {
+	return slider.samples, slider.sampleSets, slider.additionSets
+}
+
 func (slider *Slider) GetStartAngleMod(diff *difficulty.Difficulty) float32 {
 	return slider.GetStackedStartPositionMod(diff).AngleRV(slider.GetStackedPositionAtMod(slider.StartTime+min(10, slider.partLen), diff)) //temporary solution
 }
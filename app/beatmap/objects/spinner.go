@@ -92,6 +92,10 @@ func NewDummySpinner(startTime, endTime float64) *Spinner {
 	}
 }
 
+func (spinner *Spinner) GetSample() int {
+	return spinner.sample
+}
+
 func (spinner *Spinner) GetPosition() vector.Vector2f {
 	return spinner.pos
 }
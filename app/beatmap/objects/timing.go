@@ -40,6 +40,11 @@ func (t TimingPoint) GetRatio2() float64 {
 	return 100 / (-t.beatLength)
 }
 
+// GetRawBeatLength returns beat length as it was defined in .osu file, negative values are slider velocity multipliers
+func (t TimingPoint) GetRawBeatLength() float64 {
+	return t.beatLength
+}
+
 func (t TimingPoint) GetBaseBeatLength() float64 {
 	return t.beatLengthBase
 }
@@ -170,6 +175,10 @@ func (tim *Timings) GetTickDistance(point TimingPoint) float64 {
 	return tim.GetScoringDistance() / point.GetRatio()
 }
 
+func (tim *Timings) GetPoints() []TimingPoint {
+	return tim.points
+}
+
 func (tim *Timings) HasPoints() bool {
 	return len(tim.points) > 0
 }
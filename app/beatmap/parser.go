@@ -2,12 +2,15 @@ package beatmap
 
 import (
 	"cmp"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/skin"
 	"github.com/wieku/danser-go/framework/files"
 	"github.com/wieku/danser-go/framework/math/mutils"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -102,6 +105,10 @@ func parseEvents(line []string, beatMap *BeatMap) {
 	switch line[0] {
 	case "Background", "0":
 		beatMap.Bg = strings.Replace(line[2], "\"", "", -1)
+	case "Video", "1":
+		if len(line) > 2 {
+			beatMap.Video = strings.TrimSpace(strings.ReplaceAll(line[2], "\"", ""))
+		}
 	case "Break", "2":
 		beatMap.Pauses = append(beatMap.Pauses, NewPause(line))
 	}
@@ -239,6 +246,13 @@ func ParseBeatMap(beatMap *BeatMap) error {
 }
 
 func ParseBeatMapFile(file *os.File) *BeatMap {
+	beatMap, _ := TryParseBeatMapFile(file)
+
+	return beatMap
+}
+
+// TryParseBeatMapFile works like ParseBeatMapFile but returns the reason why file couldn't be parsed
+func TryParseBeatMapFile(file *os.File) (*BeatMap, error) {
 	beatMap := NewBeatMap()
 	beatMap.Dir, _ = filepath.Rel(settings.General.GetSongsDir(), filepath.Dir(file.Name()))
 	beatMap.Dir = filepath.ToSlash(beatMap.Dir)
@@ -246,13 +260,91 @@ func ParseBeatMapFile(file *os.File) *BeatMap {
 	f, _ := file.Stat()
 	beatMap.File = f.Name()
 
-	err := ParseBeatMap(beatMap)
+	if err := ParseBeatMap(beatMap); err != nil {
+		return nil, err
+	}
+
+	return beatMap, nil
+}
 
+// ParseHeaderExtras reads values that osu!.db doesn't store: sample set, slider tick rate, background and video.
+// Parsing stops at [TimingPoints] section so hit objects are never read.
+func ParseHeaderExtras(beatMap *BeatMap) error {
+	file, err := os.Open(filepath.Join(settings.General.GetSongsDir(), beatMap.Dir, beatMap.File))
 	if err != nil {
-		return nil
+		return err
 	}
 
-	return beatMap
+	defer file.Close()
+
+	scanner := files.NewScanner(file)
+
+	buf := bufferPool.Get().(*[]byte)
+	scanner.Buffer(*buf, cap(*buf))
+
+	defer bufferPool.Put(buf)
+
+	var currentSection string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		section := getSection(line)
+		if section != "" {
+			if section == "TimingPoints" || section == "HitObjects" {
+				break
+			}
+
+			currentSection = section
+			continue
+		}
+
+		switch currentSection {
+		case "General":
+			if arr := tokenizeN(line, ":", 2); len(arr) > 1 && arr[0] == "SampleSet" {
+				parseGeneral(arr, beatMap)
+			}
+		case "Difficulty":
+			if arr := tokenizeN(line, ":", 2); len(arr) > 1 && arr[0] == "SliderTickRate" {
+				parseDifficulty(arr, beatMap)
+			}
+		case "Events":
+			if arr := tokenize(line, ","); len(arr) > 2 && (arr[0] == "0" || arr[0] == "Background" || arr[0] == "1" || arr[0] == "Video") {
+				parseEvents(arr, beatMap)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// LoadBeatMapFile loads .osu file that doesn't have to be in the database
+func LoadBeatMapFile(path string) (*BeatMap, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	beatMap := ParseBeatMapFile(file)
+	if beatMap == nil {
+		return nil, errors.New("failed to parse beatmap")
+	}
+
+	hash := md5.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	beatMap.MD5 = hex.EncodeToString(hash.Sum(nil))
+
+	return beatMap, nil
 }
 
 func ParseTimingPointsAndPauses(beatMap *BeatMap) {
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type field struct {
+	names []string
+
+	// number returns value of a numeric field, nil for text fields
+	number func(b *beatmap.BeatMap) float64
+
+	// text returns values of a text field, the first one is used for sorting. nil for numeric fields
+	text func(b *beatmap.BeatMap) []string
+
+	// parse converts user input to field's unit, returns value and tolerance used in equality checks
+	parse func(value string) (float64, float64, error)
+}
+
+var fields = []*field{
+	numberField(func(b *beatmap.BeatMap) float64 { return b.Stars }, "stars", "sr"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.Diff.GetAR() }, "ar"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.Diff.GetCS() }, "cs", "keys"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.Diff.GetOD() }, "od"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.Diff.GetHP() }, "hp"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.MaxBPM }, "bpm", "maxbpm"),
+	numberField(func(b *beatmap.BeatMap) float64 { return b.MinBPM }, "minbpm"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.Circles) }, "circles"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.Sliders) }, "sliders"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.Spinners) }, "spinners"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.Circles + b.Sliders + b.Spinners) }, "objects"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.ID) }, "id"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.SetID) }, "setid"),
+	numberField(func(b *beatmap.BeatMap) float64 { return float64(b.PlayCount) }, "plays", "playcount"),
+	numberField(func(b *beatmap.BeatMap) float64 { return boolValue(b.Assets.Broken()) }, "broken"),
+	numberField(func(b *beatmap.BeatMap) float64 { return boolValue(b.Assets.Has(beatmap.BackgroundMissing)) }, "missingbg"),
+	numberField(func(b *beatmap.BeatMap) float64 { return boolValue(b.Assets.Has(beatmap.VideoMissing)) }, "missingvideo"),
+	{
+		names:  []string{"length", "len"},
+		number: func(b *beatmap.BeatMap) float64 { return float64(b.Length) },
+		parse:  parseLength,
+	},
+	{
+		names:  []string{"mode", "m"},
+		number: func(b *beatmap.BeatMap) float64 { return float64(b.Mode) },
+		parse:  parseMode,
+	},
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Artist, b.ArtistUnicode} }, "artist"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Name, b.NameUnicode} }, "title"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Difficulty} }, "difficulty", "diff", "version"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Creator} }, "creator", "mapper"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Source} }, "source"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.Tags} }, "tags"),
+	textField(func(b *beatmap.BeatMap) []string { return []string{b.MD5} }, "md5"),
+}
+
+func numberField(number func(b *beatmap.BeatMap) float64, names ...string) *field {
+	return &field{
+		names:  names,
+		number: number,
+		parse:  parseNumber,
+	}
+}
+
+func textField(text func(b *beatmap.BeatMap) []string, names ...string) *field {
+	return &field{
+		names: names,
+		text:  text,
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func findField(name string) *field {
+	for _, f := range fields {
+		for _, n := range f.names {
+			if strings.EqualFold(n, name) {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseNumber parses a number, tolerance is half of the last specified decimal place so stars=6.5 matches 6.45-6.55
+func parseNumber(value string) (float64, float64, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("\"%s\" is not a number", value)
+	}
+
+	decimals := 0
+	if i := strings.IndexByte(value, '.'); i > -1 {
+		decimals = len(value) - i - 1
+	}
+
+	return number, 0.5 * math.Pow(10, -float64(decimals)), nil
+}
+
+// parseLength parses length in milliseconds. Accepts "90", "90s", "1.5m", "1:30" and "90000ms", plain numbers are seconds.
+func parseLength(value string) (float64, float64, error) {
+	if minutes, seconds, ok := strings.Cut(value, ":"); ok {
+		m, err1 := strconv.Atoi(minutes)
+		s, err2 := strconv.Atoi(seconds)
+
+		if err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("\"%s\" is not a valid length", value)
+		}
+
+		return float64(m*60000 + s*1000), 500, nil
+	}
+
+	unit := 1000.0
+
+	switch {
+	case strings.HasSuffix(value, "ms"):
+		unit = 1
+		value = strings.TrimSuffix(value, "ms")
+	case strings.HasSuffix(value, "s"):
+		value = strings.TrimSuffix(value, "s")
+	case strings.HasSuffix(value, "m"):
+		unit = 60000
+		value = strings.TrimSuffix(value, "m")
+	case strings.HasSuffix(value, "h"):
+		unit = 3600000
+		value = strings.TrimSuffix(value, "h")
+	}
+
+	number, tolerance, err := parseNumber(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("\"%s\" is not a valid length", value)
+	}
+
+	return number * unit, tolerance * unit, nil
+}
+
+// parseMode accepts mode number or its name
+func parseMode(value string) (float64, float64, error) {
+	switch strings.ToLower(value) {
+	case "0", "osu", "std", "standard":
+		return 0, 0.5, nil
+	case "1", "taiko":
+		return 1, 0.5, nil
+	case "2", "catch", "ctb", "fruits":
+		return 2, 0.5, nil
+	case "3", "mania":
+		return 3, 0.5, nil
+	}
+
+	return 0, 0, fmt.Errorf("\"%s\" is not a valid mode", value)
+}
@@ -0,0 +1,365 @@
+// Package query implements a small expression language for selecting beatmaps.
+//
+// A query is a list of whitespace separated terms, for example:
+//
+//	stars>6.5 bpm<=200 creator=Sotarks length>120s mode=0 sort=-stars pick=2
+//
+// Terms in form key<op>value compare beatmap's field with the value, supported operators are
+// =, !=, <, <=, >, >= and : (substring match for text fields, equality for numbers).
+// Values containing spaces have to be quoted: title="Brain Power".
+// Terms without an operator are matched against artist, title, difficulty, creator, source and tags.
+// Callers may handle them with a full-text index instead, see Words and ClearWords.
+// sort=field sorts the results in ascending order, sort=-field in descending order, it can be used multiple times.
+// pick=N selects the Nth result (negative values count from the end), pick=random selects a random one.
+package query
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type operator string
+
+const (
+	equal        = operator("=")
+	notEqual     = operator("!=")
+	less         = operator("<")
+	lessEqual    = operator("<=")
+	greater      = operator(">")
+	greaterEqual = operator(">=")
+	contains     = operator(":")
+)
+
+// Two-character operators go first so "<=" isn't read as "<"
+var operators = []operator{notEqual, lessEqual, greaterEqual, equal, less, greater, contains}
+
+type condition struct {
+	field *field
+	op    operator
+
+	number    float64
+	tolerance float64
+	text      string
+}
+
+type sortKey struct {
+	field      *field
+	descending bool
+}
+
+type Query struct {
+	conditions []condition
+	words      []string
+	sorting    []sortKey
+
+	pick   int
+	random bool
+}
+
+// Parse parses the query, see package documentation for syntax
+func Parse(text string) (*Query, error) {
+	terms, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{pick: 1}
+
+	for _, term := range terms {
+		key, op, value := splitTerm(term)
+
+		if op == "" {
+			q.words = append(q.words, strings.ToLower(term))
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "sort":
+			if op != equal {
+				return nil, fmt.Errorf("sort: unsupported operator \"%s\"", op)
+			}
+
+			sKey := sortKey{}
+
+			if strings.HasPrefix(value, "-") {
+				sKey.descending = true
+				value = value[1:]
+			}
+
+			if sKey.field = findField(value); sKey.field == nil {
+				return nil, fmt.Errorf("sort: unknown field \"%s\"", value)
+			}
+
+			q.sorting = append(q.sorting, sKey)
+		case "pick":
+			if op != equal {
+				return nil, fmt.Errorf("pick: unsupported operator \"%s\"", op)
+			}
+
+			if strings.EqualFold(value, "random") {
+				q.random = true
+				continue
+			}
+
+			if q.pick, err = strconv.Atoi(value); err != nil || q.pick == 0 {
+				return nil, fmt.Errorf("pick: \"%s\" is not a valid index", value)
+			}
+		default:
+			cond, err := parseCondition(key, op, value)
+			if err != nil {
+				return nil, err
+			}
+
+			q.conditions = append(q.conditions, cond)
+		}
+	}
+
+	return q, nil
+}
+
+// ParseWords creates a query matching all words of the text, without interpreting operators and keywords
+func ParseWords(text string) *Query {
+	return &Query{
+		words: strings.Fields(strings.ToLower(text)),
+		pick:  1,
+	}
+}
+
+func parseCondition(key string, op operator, value string) (condition, error) {
+	cond := condition{
+		field: findField(key),
+		op:    op,
+	}
+
+	if cond.field == nil {
+		return cond, fmt.Errorf("unknown field \"%s\"", key)
+	}
+
+	if cond.field.text != nil {
+		if op != equal && op != notEqual && op != contains {
+			return cond, fmt.Errorf("%s: operator \"%s\" can't be used with text", key, op)
+		}
+
+		cond.text = strings.ToLower(value)
+
+		return cond, nil
+	}
+
+	if op == contains {
+		cond.op = equal
+	}
+
+	var err error
+	if cond.number, cond.tolerance, err = cond.field.parse(value); err != nil {
+		return cond, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return cond, nil
+}
+
+// tokenize splits the query by whitespace, keeping quoted values together
+func tokenize(text string) (terms []string, err error) {
+	var current strings.Builder
+
+	quoted := false
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+
+	return
+}
+
+// splitTerm splits key<op>value term, key has to consist of letters only
+func splitTerm(term string) (key string, op operator, value string) {
+	i := strings.IndexFunc(term, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+
+	if i <= 0 {
+		return term, "", ""
+	}
+
+	for _, o := range operators {
+		if strings.HasPrefix(term[i:], string(o)) {
+			return term[:i], o, term[i+len(o):]
+		}
+	}
+
+	return term, "", ""
+}
+
+// UsesStars returns true if query depends on star rating, so it has to be calculated beforehand
+func (q *Query) UsesStars() bool {
+	starsField := findField("stars")
+
+	return slices.ContainsFunc(q.conditions, func(c condition) bool { return c.field == starsField }) ||
+		slices.ContainsFunc(q.sorting, func(s sortKey) bool { return s.field == starsField })
+}
+
+// Prefilter returns beatmaps matching conditions that don't depend on star rating, in original order.
+// It's used to calculate star rating only for beatmaps that can match the query.
+func (q *Query) Prefilter(beatmaps []*beatmap.BeatMap) []*beatmap.BeatMap {
+	starsField := findField("stars")
+
+	results := make([]*beatmap.BeatMap, 0)
+
+	for _, b := range beatmaps {
+		if q.matchesIf(b, func(c condition) bool { return c.field != starsField }) {
+			results = append(results, b)
+		}
+	}
+
+	return results
+}
+
+// Words returns terms without an operator joined with spaces
+func (q *Query) Words() string {
+	return strings.Join(q.words, " ")
+}
+
+// ClearWords removes terms without an operator, used when they were already matched by a full-text search
+func (q *Query) ClearWords() {
+	q.words = nil
+}
+
+// Filter returns sorted beatmaps that match the query
+func (q *Query) Filter(beatmaps []*beatmap.BeatMap) []*beatmap.BeatMap {
+	results := make([]*beatmap.BeatMap, 0)
+
+	for _, b := range beatmaps {
+		if q.matches(b) {
+			results = append(results, b)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b *beatmap.BeatMap) int {
+		for _, s := range q.sorting {
+			var res int
+
+			if s.field.text != nil {
+				res = strings.Compare(strings.ToLower(s.field.text(a)[0]), strings.ToLower(s.field.text(b)[0]))
+			} else {
+				res = cmp.Compare(s.field.number(a), s.field.number(b))
+			}
+
+			if s.descending {
+				res = -res
+			}
+
+			if res != 0 {
+				return res
+			}
+		}
+
+		return 0
+	})
+
+	return results
+}
+
+// Pick selects a single beatmap from filtered results, returns nil if pick index is out of range
+func (q *Query) Pick(results []*beatmap.BeatMap) *beatmap.BeatMap {
+	if len(results) == 0 {
+		return nil
+	}
+
+	if q.random {
+		return results[rand.Intn(len(results))]
+	}
+
+	index := q.pick - 1
+	if q.pick < 0 {
+		index = len(results) + q.pick
+	}
+
+	if index < 0 || index >= len(results) {
+		return nil
+	}
+
+	return results[index]
+}
+
+func (q *Query) matches(b *beatmap.BeatMap) bool {
+	return q.matchesIf(b, func(condition) bool { return true })
+}
+
+// matchesIf checks only conditions accepted by the filter, words are always checked
+func (q *Query) matchesIf(b *beatmap.BeatMap, filter func(condition) bool) bool {
+	for _, c := range q.conditions {
+		if filter(c) && !c.matches(b) {
+			return false
+		}
+	}
+
+	if len(q.words) > 0 {
+		haystack := strings.ToLower(strings.Join([]string{b.Artist, b.ArtistUnicode, b.Name, b.NameUnicode, b.Difficulty, b.Creator, b.Source, b.Tags}, " "))
+
+		for _, w := range q.words {
+			if !strings.Contains(haystack, w) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c condition) matches(b *beatmap.BeatMap) bool {
+	if c.field.text != nil {
+		values := c.field.text(b)
+
+		switch c.op {
+		case equal:
+			return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, c.text) })
+		case notEqual:
+			return !slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, c.text) })
+		default:
+			return slices.ContainsFunc(values, func(v string) bool { return strings.Contains(strings.ToLower(v), c.text) })
+		}
+	}
+
+	value := c.field.number(b)
+
+	switch c.op {
+	case equal:
+		return math.Abs(value-c.number) < c.tolerance
+	case notEqual:
+		return math.Abs(value-c.number) >= c.tolerance
+	case less:
+		return value < c.number
+	case lessEqual:
+		return value <= c.number
+	case greater:
+		return value > c.number
+	case greaterEqual:
+		return value >= c.number
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package beatmap
+
+import (
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/files"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sections written by WriteBeatMap, other sections are copied as they are
+var writtenSections = []string{"General", "Editor", "Metadata", "Difficulty", "Events", "TimingPoints", "Colours", "HitObjects"} //nolint:misspell
+
+// Keys written from BeatMap's fields, other keys of those sections are copied from the original file
+var writtenKeys = map[string][]string{
+	"General":    {"AudioFilename", "PreviewTime", "SampleSet", "StackLeniency", "Mode"},
+	"Metadata":   {"Title", "TitleUnicode", "Artist", "ArtistUnicode", "Creator", "Version", "Source", "Tags", "BeatmapID", "BeatmapSetID"},
+	"Difficulty": {"HPDrainRate", "CircleSize", "OverallDifficulty", "ApproachRate", "SliderMultiplier", "SliderTickRate"},
+}
+
+// rawSections holds non-empty lines of beatmap's original file by section
+type rawSections struct {
+	order []string
+	lines map[string][]string
+}
+
+type rawEvents struct {
+	background string
+	video      string
+	other      []string
+}
+
+// readRawSections reads the original file of the beatmap, it's empty if the file can't be read
+func readRawSections(beatMap *BeatMap) *rawSections {
+	raw := &rawSections{lines: make(map[string][]string)}
+
+	if beatMap.File == "" {
+		return raw
+	}
+
+	file, err := os.Open(filepath.Join(settings.General.GetSongsDir(), beatMap.Dir, beatMap.File))
+	if err != nil {
+		return raw
+	}
+
+	defer file.Close()
+
+	scanner := files.NewScanner(file)
+
+	buf := bufferPool.Get().(*[]byte)
+	scanner.Buffer(*buf, cap(*buf))
+
+	defer bufferPool.Put(buf)
+
+	var currentSection string
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r\n")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if section := getSection(line); section != "" {
+			currentSection = section
+
+			if _, ok := raw.lines[section]; !ok {
+				raw.order = append(raw.order, section)
+				raw.lines[section] = make([]string, 0)
+			}
+
+			continue
+		}
+
+		if currentSection != "" {
+			raw.lines[currentSection] = append(raw.lines[currentSection], line)
+		}
+	}
+
+	return raw
+}
+
+// extraKeys returns lines of key-value section that are not written from BeatMap's fields
+func (raw *rawSections) extraKeys(section string) (lines []string) {
+	for _, line := range raw.lines[section] {
+		if arr := tokenizeN(line, ":", 2); arr == nil || !slices.Contains(writtenKeys[section], arr[0]) {
+			lines = append(lines, line)
+		}
+	}
+
+	return
+}
+
+// events splits events into background and video lines that still match the beatmap and lines that are copied as
+// they are (storyboard, samples). Breaks are always written from BeatMap's pauses.
+func (raw *rawSections) events(beatMap *BeatMap) (events rawEvents) {
+	for _, line := range raw.lines["Events"] {
+		if line == "//Background and Video events" || line == "//Break Periods" {
+			continue
+		}
+
+		// Indented lines are storyboard commands
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "_") {
+			events.other = append(events.other, line)
+			continue
+		}
+
+		arr := tokenize(line, ",")
+		if arr == nil {
+			events.other = append(events.other, line)
+			continue
+		}
+
+		switch arr[0] {
+		case "Background", "0":
+			if len(arr) > 2 && eventFile(arr[2]) == beatMap.Bg {
+				events.background = line
+			}
+		case "Video", "1":
+			if len(arr) > 2 && eventFile(arr[2]) == beatMap.Video {
+				events.video = line
+			}
+		case "Break", "2":
+		default:
+			events.other = append(events.other, line)
+		}
+	}
+
+	return
+}
+
+func eventFile(value string) string {
+	return strings.TrimSpace(strings.ReplaceAll(value, "\"", ""))
+}
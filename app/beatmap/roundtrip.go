@@ -0,0 +1,124 @@
+package beatmap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// CheckRoundTrip writes the beatmap with WriteBeatMap to a temporary file, parses it again and returns the differences
+// from the original. Beatmap has to have its timing points and objects parsed beforehand.
+func CheckRoundTrip(beatMap *BeatMap) ([]string, error) {
+	dir, err := os.MkdirTemp("", "danser-roundtrip")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(beatMap.File))
+
+	if err = SaveBeatMapFile(beatMap, path); err != nil {
+		return nil, err
+	}
+
+	written, err := LoadBeatMapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ParseTimingPointsAndPauses(written)
+	ParseObjects(written, true, false)
+
+	diffs := compareBeatMaps(beatMap, written)
+
+	original, rewritten := readRawSections(beatMap), readRawSections(written)
+
+	for _, section := range original.order {
+		if section == "TimingPoints" || section == "HitObjects" {
+			continue
+		}
+
+		var a, b []string
+
+		switch section {
+		case "General", "Metadata", "Difficulty":
+			a, b = original.extraKeys(section), rewritten.extraKeys(section)
+		case "Events":
+			a, b = original.events(beatMap).other, rewritten.events(written).other
+		default:
+			a, b = original.lines[section], rewritten.lines[section]
+		}
+
+		if !slices.Equal(a, b) {
+			diffs = append(diffs, fmt.Sprintf("[%s]: %d preserved lines, %d after writing", section, len(a), len(b)))
+		}
+	}
+
+	return diffs, nil
+}
+
+func compareBeatMaps(a, b *BeatMap) (diffs []string) {
+	compare := func(name string, v1, v2 any) {
+		if v1 != v2 {
+			diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, v1, v2))
+		}
+	}
+
+	compare("Title", a.Name, b.Name)
+	compare("TitleUnicode", a.NameUnicode, b.NameUnicode)
+	compare("Artist", a.Artist, b.Artist)
+	compare("ArtistUnicode", a.ArtistUnicode, b.ArtistUnicode)
+	compare("Creator", a.Creator, b.Creator)
+	compare("Version", a.Difficulty, b.Difficulty)
+	compare("Source", a.Source, b.Source)
+	compare("Tags", a.Tags, b.Tags)
+	compare("BeatmapID", a.ID, b.ID)
+	compare("BeatmapSetID", a.SetID, b.SetID)
+	compare("Mode", a.Mode, b.Mode)
+	compare("AudioFilename", a.Audio, b.Audio)
+	compare("PreviewTime", a.PreviewTime, b.PreviewTime)
+	compare("SampleSet", a.Timings.BaseSet, b.Timings.BaseSet)
+	compare("StackLeniency", a.StackLeniency, b.StackLeniency)
+	compare("HPDrainRate", a.Diff.GetHP(), b.Diff.GetHP())
+	compare("CircleSize", a.Diff.GetCS(), b.Diff.GetCS())
+	compare("OverallDifficulty", a.Diff.GetOD(), b.Diff.GetOD())
+	compare("ApproachRate", a.Diff.GetAR(), b.Diff.GetAR())
+	compare("SliderMultiplier", a.Timings.SliderMult, b.Timings.SliderMult)
+	compare("SliderTickRate", a.Timings.TickRate, b.Timings.TickRate)
+	compare("Background", a.Bg, b.Bg)
+	compare("Video", a.Video, b.Video)
+
+	compare("Breaks", len(a.Pauses), len(b.Pauses))
+
+	for i := 0; i < min(len(a.Pauses), len(b.Pauses)); i++ {
+		compare(fmt.Sprintf("Break %d", i), *a.Pauses[i], *b.Pauses[i])
+	}
+
+	pointsA, pointsB := a.Timings.GetPoints(), b.Timings.GetPoints()
+
+	compare("Timing points", len(pointsA), len(pointsB))
+
+	for i := 0; i < min(len(pointsA), len(pointsB)); i++ {
+		compare(fmt.Sprintf("Timing point at %.0fms", pointsA[i].Time), pointsA[i], pointsB[i])
+	}
+
+	compare("Hit objects", len(a.HitObjects), len(b.HitObjects))
+
+	for i := 0; i < min(len(a.HitObjects), len(b.HitObjects)); i++ {
+		o1, o2 := a.HitObjects[i], b.HitObjects[i]
+
+		name := fmt.Sprintf("Object at %.0fms", o1.GetStartTime())
+
+		compare(name+" type", o1.GetType(), o2.GetType())
+		compare(name+" start time", o1.GetStartTime(), o2.GetStartTime())
+		compare(name+" end time", o1.GetEndTime(), o2.GetEndTime())
+		compare(name+" start position", o1.GetStartPosition(), o2.GetStartPosition())
+		compare(name+" end position", o1.GetEndPosition(), o2.GetEndPosition())
+		compare(name+" new combo", o1.IsNewCombo(), o2.IsNewCombo())
+		compare(name+" colour offset", o1.GetColorOffset(), o2.GetColorOffset())
+	}
+
+	return
+}
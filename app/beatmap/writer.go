@@ -0,0 +1,306 @@
+package beatmap
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/wieku/danser-go/app/audio"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/curves"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const writerVersion = 14
+
+// WriteBeatMap serializes the beatmap in .osu format.
+// Beatmap has to have its timing points and objects parsed beforehand, spinners are written only if they were loaded.
+// Sections, keys and events that danser doesn't parse (e.g. storyboard, colours, editor settings) are copied from
+// the beatmap's original file.
+func WriteBeatMap(beatMap *BeatMap, w io.Writer) error {
+	return writeBeatMap(beatMap, readRawSections(beatMap), w)
+}
+
+// SaveBeatMapFile writes the beatmap to given path, creating missing directories. Path can point to the original file.
+func SaveBeatMapFile(beatMap *BeatMap, path string) error {
+	// Read before creating the file, it may be the one being overwritten
+	raw := readRawSections(beatMap)
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return writeBeatMap(beatMap, raw, file)
+}
+
+func writeBeatMap(beatMap *BeatMap, raw *rawSections, w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	version := beatMap.Version
+	if version <= 0 {
+		version = writerVersion
+	}
+
+	fmt.Fprintf(writer, "osu file format v%d\r\n", version)
+
+	writeGeneral(writer, beatMap, raw)
+	writeRawSection(writer, raw, "Editor")
+	writeMetadata(writer, beatMap, raw)
+	writeDifficulty(writer, beatMap, raw)
+	writeEvents(writer, beatMap, raw)
+	writeTimingPoints(writer, beatMap)
+	writeRawSection(writer, raw, "Colours") //nolint:misspell
+
+	if err := writeHitObjects(writer, beatMap); err != nil {
+		return err
+	}
+
+	for _, section := range raw.order {
+		if !slices.Contains(writtenSections, section) {
+			writeRawSection(writer, raw, section)
+		}
+	}
+
+	return writer.Flush()
+}
+
+func writeGeneral(w *bufio.Writer, beatMap *BeatMap, raw *rawSections) {
+	sampleSet := "Normal"
+
+	switch beatMap.Timings.BaseSet {
+	case 2:
+		sampleSet = "Soft"
+	case 3:
+		sampleSet = "Drum"
+	}
+
+	w.WriteString("\r\n[General]\r\n")
+	fmt.Fprintf(w, "AudioFilename: %s\r\n", beatMap.Audio)
+	fmt.Fprintf(w, "PreviewTime: %d\r\n", beatMap.PreviewTime)
+	fmt.Fprintf(w, "SampleSet: %s\r\n", sampleSet)
+	fmt.Fprintf(w, "StackLeniency: %s\r\n", formatFloat(beatMap.StackLeniency))
+	fmt.Fprintf(w, "Mode: %d\r\n", beatMap.Mode)
+
+	writeLines(w, raw.extraKeys("General"))
+}
+
+func writeMetadata(w *bufio.Writer, beatMap *BeatMap, raw *rawSections) {
+	w.WriteString("\r\n[Metadata]\r\n")
+	fmt.Fprintf(w, "Title:%s\r\n", beatMap.Name)
+	fmt.Fprintf(w, "TitleUnicode:%s\r\n", beatMap.NameUnicode)
+	fmt.Fprintf(w, "Artist:%s\r\n", beatMap.Artist)
+	fmt.Fprintf(w, "ArtistUnicode:%s\r\n", beatMap.ArtistUnicode)
+	fmt.Fprintf(w, "Creator:%s\r\n", beatMap.Creator)
+	fmt.Fprintf(w, "Version:%s\r\n", beatMap.Difficulty)
+	fmt.Fprintf(w, "Source:%s\r\n", beatMap.Source)
+	fmt.Fprintf(w, "Tags:%s\r\n", beatMap.Tags)
+	fmt.Fprintf(w, "BeatmapID:%d\r\n", beatMap.ID)
+	fmt.Fprintf(w, "BeatmapSetID:%d\r\n", beatMap.SetID)
+
+	writeLines(w, raw.extraKeys("Metadata"))
+}
+
+func writeDifficulty(w *bufio.Writer, beatMap *BeatMap, raw *rawSections) {
+	w.WriteString("\r\n[Difficulty]\r\n")
+	fmt.Fprintf(w, "HPDrainRate:%s\r\n", formatFloat(beatMap.Diff.GetHP()))
+	fmt.Fprintf(w, "CircleSize:%s\r\n", formatFloat(beatMap.Diff.GetCS()))
+	fmt.Fprintf(w, "OverallDifficulty:%s\r\n", formatFloat(beatMap.Diff.GetOD()))
+	fmt.Fprintf(w, "ApproachRate:%s\r\n", formatFloat(beatMap.Diff.GetAR()))
+	fmt.Fprintf(w, "SliderMultiplier:%s\r\n", formatFloat(beatMap.Timings.SliderMult))
+	fmt.Fprintf(w, "SliderTickRate:%s\r\n", formatFloat(beatMap.Timings.TickRate))
+
+	writeLines(w, raw.extraKeys("Difficulty"))
+}
+
+func writeEvents(w *bufio.Writer, beatMap *BeatMap, raw *rawSections) {
+	w.WriteString("\r\n[Events]\r\n")
+
+	events := raw.events(beatMap)
+
+	w.WriteString("//Background and Video events\r\n")
+
+	// Original lines are kept if files didn't change, they may contain offsets and start time
+	if events.background != "" {
+		fmt.Fprintf(w, "%s\r\n", events.background)
+	} else if beatMap.Bg != "" {
+		fmt.Fprintf(w, "0,0,\"%s\",0,0\r\n", beatMap.Bg)
+	}
+
+	if events.video != "" {
+		fmt.Fprintf(w, "%s\r\n", events.video)
+	} else if beatMap.Video != "" {
+		fmt.Fprintf(w, "Video,0,\"%s\"\r\n", beatMap.Video)
+	}
+
+	w.WriteString("//Break Periods\r\n")
+
+	for _, pause := range beatMap.Pauses {
+		fmt.Fprintf(w, "2,%s,%s\r\n", formatFloat(pause.StartTime), formatFloat(pause.EndTime))
+	}
+
+	writeLines(w, events.other)
+}
+
+func writeTimingPoints(w *bufio.Writer, beatMap *BeatMap) {
+	w.WriteString("\r\n[TimingPoints]\r\n")
+
+	for _, point := range beatMap.Timings.GetPoints() {
+		uninherited := 1
+		if point.Inherited {
+			uninherited = 0
+		}
+
+		effects := 0
+		if point.Kiai {
+			effects |= 1
+		}
+
+		if point.OmitFirstBarLine {
+			effects |= 8
+		}
+
+		fmt.Fprintf(w, "%s,%s,%d,%d,%d,%d,%d,%d\r\n",
+			formatFloat(point.Time),
+			formatFloat(point.GetRawBeatLength()),
+			point.Signature,
+			point.SampleSet,
+			point.SampleIndex,
+			int(math.Round(point.SampleVolume*100)),
+			uninherited,
+			effects,
+		)
+	}
+}
+
+func writeHitObjects(w *bufio.Writer, beatMap *BeatMap) error {
+	w.WriteString("\r\n[HitObjects]\r\n")
+
+	for _, obj := range beatMap.HitObjects {
+		var line string
+
+		switch o := obj.(type) {
+		case *objects.Circle:
+			line = fmt.Sprintf("%s,%d,%s", writeCommon(o.HitObject, objects.CIRCLE), o.GetSample(), writeExtras(o.BasicHitSound))
+		case *objects.Spinner:
+			line = fmt.Sprintf("%s,%d,%s,%s", writeCommon(o.HitObject, objects.SPINNER), o.GetSample(), formatFloat(o.EndTime), writeExtras(o.BasicHitSound))
+		case *objects.Slider:
+			line = writeSlider(o)
+		default:
+			return fmt.Errorf("unsupported hit object at %.0fms", obj.GetStartTime())
+		}
+
+		w.WriteString(line)
+		w.WriteString("\r\n")
+	}
+
+	return nil
+}
+
+func writeCommon(hitObject *objects.HitObject, objType objects.Type) string {
+	if hitObject.NewCombo {
+		objType |= objects.NEWCOMBO
+	}
+
+	objType |= objects.Type(hitObject.ColorOffset&7) << 4
+
+	return fmt.Sprintf("%s,%s,%s,%d", formatFloat32(hitObject.StartPosRaw.X), formatFloat32(hitObject.StartPosRaw.Y), formatFloat(hitObject.StartTime), objType)
+}
+
+func writeSlider(slider *objects.Slider) string {
+	var curve strings.Builder
+
+	defs := slider.GetCurveDefs()
+
+	for i, def := range defs {
+		if i == 0 {
+			curve.WriteString(curveTypeName(def.CurveType))
+		}
+
+		// First point of the first segment is slider's start position, first points of the next segments are shared with previous ones
+		for j := 1; j < len(def.Points); j++ {
+			if j == len(def.Points)-1 && i < len(defs)-1 {
+				curve.WriteString("|")
+				curve.WriteString(curveTypeName(defs[i+1].CurveType))
+			}
+
+			curve.WriteString(fmt.Sprintf("|%s:%s", formatFloat32(def.Points[j].X), formatFloat32(def.Points[j].Y)))
+		}
+	}
+
+	samples, sampleSets, additionSets := slider.GetEdgeSamples()
+
+	edgeSamples := make([]string, len(samples))
+	edgeSets := make([]string, len(samples))
+
+	for i := range samples {
+		edgeSamples[i] = strconv.Itoa(samples[i])
+		edgeSets[i] = fmt.Sprintf("%d:%d", sampleSets[i], additionSets[i])
+	}
+
+	return fmt.Sprintf("%s,%d,%s,%d,%s,%s,%s,%s",
+		writeCommon(slider.HitObject, objects.SLIDER),
+		slider.GetBaseSample(),
+		curve.String(),
+		slider.RepeatCount,
+		formatFloat(slider.GetPixelLength()),
+		strings.Join(edgeSamples, "|"),
+		strings.Join(edgeSets, "|"),
+		writeExtras(slider.BasicHitSound),
+	)
+}
+
+func writeExtras(info audio.HitSoundInfo) string {
+	return fmt.Sprintf("%d:%d:%d:%d:%s", info.SampleSet, info.AdditionSet, info.CustomIndex, int(math.Round(info.CustomVolume*100)), info.Filename)
+}
+
+func curveTypeName(cType curves.CType) string {
+	switch cType {
+	case curves.CLine:
+		return "L"
+	case curves.CCirArc:
+		return "P"
+	case curves.CCatmull:
+		return "C"
+	default:
+		return "B"
+	}
+}
+
+func writeRawSection(w *bufio.Writer, raw *rawSections, section string) {
+	lines, ok := raw.lines[section]
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "\r\n[%s]\r\n", section)
+
+	writeLines(w, lines)
+}
+
+func writeLines(w *bufio.Writer, lines []string) {
+	for _, line := range lines {
+		w.WriteString(line)
+		w.WriteString("\r\n")
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatFloat32(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/query"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/framework/util"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type listedBeatmap struct {
+	MD5        string  `json:"md5"`
+	ID         int64   `json:"id"`
+	SetID      int64   `json:"setId"`
+	Mode       int64   `json:"mode"`
+	Artist     string  `json:"artist"`
+	Title      string  `json:"title"`
+	Difficulty string  `json:"difficulty"`
+	Creator    string  `json:"creator"`
+	Stars      float64 `json:"stars"`
+	MinBPM     float64 `json:"minBpm"`
+	MaxBPM     float64 `json:"maxBpm"`
+	Length     int     `json:"length"`
+	Path       string  `json:"path"`
+	Assets     string  `json:"assets"`
+}
+
+// filterByQuery returns beatmaps matching the query, words without an operator are matched using full-text index if it's available
+func filterByQuery(beatmaps []*beatmap.BeatMap, q *query.Query) []*beatmap.BeatMap {
+	if words := q.Words(); words != "" && database.SearchAvailable() {
+		if found, err := database.FilterBySearch(beatmaps, words); err != nil {
+			if !errors.Is(err, database.ErrNoSearchWords) {
+				log.Println("Full-text search failed, falling back to simple matching:", err)
+			}
+		} else {
+			beatmaps = found
+			q.ClearWords()
+		}
+	}
+
+	if q.UsesStars() {
+		// Star rating is expensive to calculate, other conditions narrow down the beatmaps first
+		beatmaps = q.Prefilter(beatmaps)
+
+		database.UpdateStarRating(beatmaps, nil)
+	}
+
+	return q.Filter(beatmaps)
+}
+
+func newListedBeatmap(b *beatmap.BeatMap) listedBeatmap {
+	return listedBeatmap{
+		MD5:        b.MD5,
+		ID:         b.ID,
+		SetID:      b.SetID,
+		Mode:       b.Mode,
+		Artist:     b.Artist,
+		Title:      b.Name,
+		Difficulty: b.Difficulty,
+		Creator:    b.Creator,
+		Stars:      b.Stars,
+		MinBPM:     b.MinBPM,
+		MaxBPM:     b.MaxBPM,
+		Length:     b.Length,
+		Path:       b.Dir + "/" + b.File,
+		Assets:     b.Assets.String(),
+	}
+}
+
+// printBeatmapList logs beatmaps matched by -query as a table, with -out the list is also saved as JSON
+func printBeatmapList(beatmaps []*beatmap.BeatMap) {
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader([]string{"#", "MD5", "Beatmap", "Creator", "Mode", "Stars", "BPM", "Length"})
+
+	listed := make([]listedBeatmap, 0, len(beatmaps))
+
+	for i, b := range beatmaps {
+		listed = append(listed, newListedBeatmap(b))
+
+		bpm := fmt.Sprintf("%.0f", b.MaxBPM)
+		if b.MaxBPM-b.MinBPM > 0.01 {
+			bpm = fmt.Sprintf("%.0f-%.0f", b.MinBPM, b.MaxBPM)
+		}
+
+		stars := "N/A"
+		if b.Stars >= 0 {
+			stars = fmt.Sprintf("%.2f", b.Stars)
+		}
+
+		table.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			b.MD5,
+			fmt.Sprintf("%s - %s [%s]", b.Artist, b.Name, b.Difficulty),
+			b.Creator,
+			fmt.Sprintf("%d", b.Mode),
+			stars,
+			bpm,
+			util.FormatSeconds(b.Length / 1000),
+		})
+	}
+
+	table.Render()
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+
+	if output == "" {
+		return
+	}
+
+	path := strings.TrimSuffix(output, filepath.Ext(output)) + ".json"
+
+	data, err := json.MarshalIndent(listed, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		panic("Failed to save the beatmap list: " + err.Error())
+	}
+
+	log.Println("Beatmap list saved to:", path)
+}
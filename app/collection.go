@@ -0,0 +1,98 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/database"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// filterByCollection returns beatmaps that are in the given collection, in collection's order
+func filterByCollection(beatmaps []*beatmap.BeatMap, name string) []*beatmap.BeatMap {
+	collection := database.FindCollection(database.LoadCollections(), name)
+	if collection == nil {
+		log.Println(fmt.Sprintf("Collection \"%s\" not found", name))
+		return nil
+	}
+
+	byMD5 := make(map[string]*beatmap.BeatMap, len(beatmaps))
+
+	for _, b := range beatmaps {
+		byMD5[strings.ToLower(b.MD5)] = b
+	}
+
+	found := make([]*beatmap.BeatMap, 0, len(collection.MD5s))
+
+	for _, md5 := range collection.MD5s {
+		if b, ok := byMD5[strings.ToLower(md5)]; ok {
+			found = append(found, b)
+		}
+	}
+
+	if missing := len(collection.MD5s) - len(found); missing > 0 {
+		log.Println(fmt.Sprintf("Collection \"%s\": %d beatmaps are not available, skipping them", collection.Name, missing))
+	}
+
+	return found
+}
+
+// runCollection runs danser separately for each beatmap with the same arguments, -out gets beatmap's index as a suffix
+func runCollection(beatmaps []*beatmap.BeatMap) {
+	executable, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+
+	baseArgs := stripFlags(os.Args[1:], "collection", "out", "nodbcheck", "noupdatecheck")
+	baseArgs = append(baseArgs, "-nodbcheck", "-noupdatecheck") // Database was already checked by this process
+
+	for i, b := range beatmaps {
+		log.Println(fmt.Sprintf("Collection: Running %d/%d: %s - %s [%s]", i+1, len(beatmaps), b.Artist, b.Name, b.Difficulty))
+
+		args := append(slices.Clone(baseArgs), "-md5="+b.MD5)
+
+		if output != "" {
+			args = append(args, fmt.Sprintf("-out=%s_%d", output, i+1))
+		}
+
+		cmd := exec.Command(executable, args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err = cmd.Run(); err != nil {
+			log.Println(fmt.Sprintf("Collection: danser failed on %s: %s", b.File, err))
+		}
+	}
+
+	log.Println("Collection: Finished!")
+}
+
+// stripFlags removes given flags together with their values from the argument list
+func stripFlags(args []string, names ...string) (ret []string) {
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+
+		if !strings.HasPrefix(args[i], "-") || !slices.Contains(names, name) {
+			ret = append(ret, args[i])
+			continue
+		}
+
+		// Non-boolean flags in "-flag value" form take the next argument as well
+		if f := flag.Lookup(name); !hasValue && f != nil && !isBoolFlag(f) {
+			i++
+		}
+	}
+
+	return
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
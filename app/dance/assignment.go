@@ -0,0 +1,282 @@
+package dance
+
+import (
+	"container/heap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"log"
+	"math"
+)
+
+const (
+	AssignRoundRobin = "RoundRobin"
+	AssignTravel     = "Travel"
+	AssignVelocity   = "Velocity"
+)
+
+// Cost of leaving an object unassigned, has to be higher than any possible sum of movement costs
+// while the sum over all objects still fits in int64
+const unassignedCost = int64(1) << 40
+
+// Velocity above that (osu!pixels per ms) costs the same, keeps costs from overflowing
+const maxAssignmentVelocity = 10.0
+
+// assignObjects splits objects between cursors so that total cost of movements is minimal. Cost is either the travelled
+// distance or the 4th power of velocity which penalizes fast movements heavily, minimizing peak velocity.
+// A cursor can move to the next object only after finishing the previous one.
+//
+// It's a minimum cost path cover with at most cursors paths, solved as minimum cost flow. Each object is a node that
+// should carry one unit of flow, objects that can't be covered because of timing conflicts are assigned round-robin.
+// Returns cursor index for each object.
+func assignObjects(objs []objects.IHitObject, diff *difficulty.Difficulty, cursors int, strategy string) []int {
+	n := len(objs)
+
+	assignment := make([]int, n)
+
+	for i := range assignment {
+		assignment[i] = i % cursors
+	}
+
+	if n == 0 || cursors < 2 {
+		return assignment
+	}
+
+	// Nodes: source, sink, object's input (2+2i) and output (3+2i)
+	source, sink := 0, 1
+	in := func(i int) int { return 2 + 2*i }
+	out := func(i int) int { return 3 + 2*i }
+
+	graph := newFlowGraph(2 + 2*n)
+
+	graph.addEdge(source, sink, int64(cursors), 0)
+
+	// Cursor can skip at most this many compatible objects, limits number of edges
+	window := 4*cursors + 4
+
+	for i, o := range objs {
+		graph.addEdge(source, in(i), 1, 0)
+		graph.addEdge(in(i), out(i), 1, -unassignedCost)
+		graph.addEdge(out(i), sink, 1, 0)
+
+		endTime := o.GetEndTime()
+		endPos := o.GetStackedEndPositionMod(diff)
+
+		added := 0
+
+		for j := i + 1; j < n && added < window; j++ {
+			next := objs[j]
+
+			if next.GetStartTime() <= endTime {
+				continue
+			}
+
+			dist := float64(endPos.Dst(next.GetStackedStartPositionMod(diff)))
+
+			var cost int64
+
+			if strategy == AssignVelocity {
+				v := min(dist/(next.GetStartTime()-endTime), maxAssignmentVelocity)
+				cost = int64(v * v * v * v * 1000)
+			} else {
+				cost = int64(dist * 100)
+			}
+
+			graph.addEdge(out(i), in(j), 1, cost)
+
+			added++
+		}
+	}
+
+	// Edges only go forward in time, so node order is topological
+	order := make([]int, 0, graph.size)
+	order = append(order, source)
+
+	for i := range objs {
+		order = append(order, in(i), out(i))
+	}
+
+	order = append(order, sink)
+
+	graph.minCostFlow(source, sink, int64(cursors), order)
+
+	// Follow flow from the source to build cursor paths
+	unassigned := 0
+
+	for i := range assignment {
+		assignment[i] = -1
+	}
+
+	cursor := 0
+
+	for _, e := range graph.edges[source] {
+		if e.to == sink || e.flow <= 0 {
+			continue
+		}
+
+		for node := e.to; node != sink; {
+			i := (node - 2) / 2
+
+			if node == in(i) {
+				assignment[i] = cursor
+				node = out(i)
+			}
+
+			next := sink
+
+			for _, e2 := range graph.edges[node] {
+				if e2.flow > 0 && e2.to != in(i) {
+					next = e2.to
+					break
+				}
+			}
+
+			node = next
+		}
+
+		cursor++
+	}
+
+	for i := range assignment {
+		if assignment[i] == -1 {
+			assignment[i] = i % cursors
+			unassigned++
+		}
+	}
+
+	if unassigned > 0 {
+		log.Println("TAG assignment:", unassigned, "objects overlap with objects of all cursors, assigned round-robin")
+	}
+
+	return assignment
+}
+
+type flowEdge struct {
+	to   int
+	rev  int
+	cap  int64
+	flow int64
+	cost int64
+}
+
+type flowGraph struct {
+	size  int
+	edges [][]*flowEdge
+}
+
+func newFlowGraph(size int) *flowGraph {
+	return &flowGraph{
+		size:  size,
+		edges: make([][]*flowEdge, size),
+	}
+}
+
+func (g *flowGraph) addEdge(from, to int, capacity, cost int64) {
+	g.edges[from] = append(g.edges[from], &flowEdge{to: to, rev: len(g.edges[to]), cap: capacity, cost: cost})
+	g.edges[to] = append(g.edges[to], &flowEdge{to: from, rev: len(g.edges[from]) - 1, cap: 0, cost: -cost})
+}
+
+// minCostFlow pushes up to maxFlow units using successive shortest paths. Graph has negative costs, so initial
+// potentials are computed in topological order, later Dijkstra works on non-negative reduced costs.
+func (g *flowGraph) minCostFlow(source, sink int, maxFlow int64, order []int) {
+	potential := make([]int64, g.size)
+
+	for i := range potential {
+		potential[i] = math.MaxInt64
+	}
+
+	potential[source] = 0
+
+	for _, u := range order {
+		if potential[u] == math.MaxInt64 {
+			continue
+		}
+
+		for _, e := range g.edges[u] {
+			if e.cap > 0 && potential[u]+e.cost < potential[e.to] {
+				potential[e.to] = potential[u] + e.cost
+			}
+		}
+	}
+
+	dist := make([]int64, g.size)
+	prevNode := make([]int, g.size)
+	prevEdge := make([]*flowEdge, g.size)
+
+	for flow := int64(0); flow < maxFlow; {
+		for i := range dist {
+			dist[i] = math.MaxInt64
+		}
+
+		dist[source] = 0
+
+		queue := &flowQueue{{node: source}}
+
+		for queue.Len() > 0 {
+			item := heap.Pop(queue).(flowItem)
+
+			if item.dist > dist[item.node] {
+				continue
+			}
+
+			for _, e := range g.edges[item.node] {
+				if e.cap-e.flow <= 0 || potential[e.to] == math.MaxInt64 {
+					continue
+				}
+
+				d := dist[item.node] + e.cost + potential[item.node] - potential[e.to]
+
+				if d < dist[e.to] {
+					dist[e.to] = d
+					prevNode[e.to] = item.node
+					prevEdge[e.to] = e
+
+					heap.Push(queue, flowItem{node: e.to, dist: d})
+				}
+			}
+		}
+
+		if dist[sink] == math.MaxInt64 {
+			break
+		}
+
+		for i := range potential {
+			if dist[i] != math.MaxInt64 && potential[i] != math.MaxInt64 {
+				potential[i] += dist[i]
+			}
+		}
+
+		push := maxFlow - flow
+
+		for node := sink; node != source; node = prevNode[node] {
+			push = min(push, prevEdge[node].cap-prevEdge[node].flow)
+		}
+
+		for node := sink; node != source; node = prevNode[node] {
+			e := prevEdge[node]
+			e.flow += push
+			g.edges[e.to][e.rev].flow -= push
+		}
+
+		flow += push
+	}
+}
+
+type flowItem struct {
+	node int
+	dist int64
+}
+
+type flowQueue []flowItem
+
+func (q flowQueue) Len() int           { return len(q) }
+func (q flowQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q flowQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *flowQueue) Push(x any)        { *q = append(*q, x.(flowItem)) }
+
+func (q *flowQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return item
+}
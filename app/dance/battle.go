@@ -0,0 +1,150 @@
+package dance
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/dance/movers"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"time"
+)
+
+// BattleController dances the whole map with every cursor like GenericController in battle mode,
+// but also judges each cursor with the ruleset so movers can be compared by score, accuracy, combo and pp
+type BattleController struct {
+	bMap     *beatmap.BeatMap
+	dance    *GenericController
+	replays  []RpData
+	ruleset  *osu.OsuRuleSet
+	lastTime float64
+}
+
+func NewBattleController() Controller {
+	return &BattleController{dance: NewGenericController().(*GenericController), lastTime: -200}
+}
+
+func (controller *BattleController) SetBeatMap(beatMap *beatmap.BeatMap) {
+	controller.bMap = beatMap
+	controller.dance.SetBeatMap(beatMap)
+}
+
+func (controller *BattleController) InitCursors() {
+	controller.dance.InitCursors()
+
+	cursors := controller.dance.GetCursors()
+
+	diffs := make([]*difficulty.Difficulty, len(cursors))
+
+	displayedMods := ^difficulty.ParseMods(settings.Knockout.HideMods)
+
+	counter := make(map[string]int)
+
+	for i, cursor := range cursors {
+		_, mName := movers.GetMoverCtorByName(cursorMover(i))
+
+		// Knockout overlay identifies players by names, so cursors using the same mover need a number
+		name := mName
+		if counter[mName] > 0 {
+			name += fmt.Sprintf(" #%d", counter[mName]+1)
+		}
+
+		counter[mName]++
+
+		cursor.Name = name
+		cursor.ScoreTime = time.Now()
+		cursor.ScoreID = -1
+		cursor.IsPlayer = true
+		cursor.IsAutoplay = true
+
+		diffs[i] = controller.bMap.Diff.Clone()
+
+		controller.replays = append(controller.replays, RpData{name, name, (diffs[i].Mods & displayedMods).String(), diffs[i].Mods, 100, 0, 0, osu.NONE, -1, cursor.ScoreTime})
+	}
+
+	controller.ruleset = osu.NewOsuRuleset(controller.bMap, cursors, diffs)
+
+	settings.PLAYERS = len(cursors)
+}
+
+func (controller *BattleController) Update(time float64, delta float64) {
+	numSkipped := int(time) - int(controller.lastTime) - 1
+
+	if numSkipped >= 1 {
+		for nTime := numSkipped; nTime >= 1; nTime-- {
+			controller.updateMain(time - float64(nTime))
+		}
+	}
+
+	controller.updateMain(time)
+
+	for i, cursor := range controller.dance.GetCursors() {
+		sc := controller.ruleset.GetScore(cursor)
+		controller.replays[i].Accuracy = sc.Accuracy
+		controller.replays[i].Combo = int64(sc.Combo)
+		controller.replays[i].Grade = sc.Grade
+	}
+}
+
+func (controller *BattleController) updateMain(nTime float64) {
+	if !settings.HEADLESS {
+		controller.bMap.Update(nTime)
+	}
+
+	controller.dance.Update(nTime, nTime-controller.lastTime)
+
+	if int64(nTime) != int64(controller.lastTime) {
+		for _, cursor := range controller.dance.GetCursors() {
+			// Spinners are judged at replay frames, so feed them at the same rate as danser's replays
+			if int64(nTime)%17 == 0 {
+				cursor.LastFrameTime = int64(nTime) - 17
+				cursor.CurrentFrameTime = int64(nTime)
+				cursor.IsReplayFrame = true
+			} else {
+				cursor.IsReplayFrame = false
+			}
+
+			controller.ruleset.UpdateClickFor(cursor, int64(nTime))
+			controller.ruleset.UpdateNormalFor(cursor, int64(nTime), false)
+			controller.ruleset.UpdatePostFor(cursor, int64(nTime), false)
+		}
+
+		controller.ruleset.Update(int64(nTime))
+	}
+
+	controller.lastTime = nTime
+}
+
+func (controller *BattleController) GetCursors() []*graphics.Cursor {
+	return controller.dance.GetCursors()
+}
+
+func (controller *BattleController) GetReplays() []RpData {
+	return controller.replays
+}
+
+func (controller *BattleController) GetRuleset() *osu.OsuRuleSet {
+	return controller.ruleset
+}
+
+func (controller *BattleController) GetBeatMap() *beatmap.BeatMap {
+	return controller.bMap
+}
+
+func (controller *BattleController) GetClick(player, key int) bool {
+	cursor := controller.dance.GetCursors()[player]
+
+	switch key {
+	case 0:
+		return cursor.LeftKey
+	case 1:
+		return cursor.RightKey
+	case 2:
+		return cursor.LeftMouse
+	case 3:
+		return cursor.RightMouse
+	}
+
+	return false
+}
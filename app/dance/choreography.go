@@ -0,0 +1,225 @@
+package dance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/dance/movers"
+	"github.com/wieku/danser-go/app/dance/schedulers"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Matches "01:05.000" and osu! editor timestamps like "01:05:000 (1,2,3) - "
+var choreographyTimeRegex = regexp.MustCompile(`^\s*(\d+):(\d{1,2})([.:])(\d{1,3})`)
+
+// Fields of settings.CursorDance.MoverSettings used by each mover
+var moverSettingsFields = map[string]string{
+	"spline":   "Spline",
+	"bezier":   "Bezier",
+	"circular": "HalfCircle",
+	"flower":   "Flower",
+	"momentum": "Momentum",
+	"exgon":    "ExGon",
+	"linear":   "Linear",
+	"pippi":    "Pippi",
+	"script":   "Script",
+	"human":    "Human",
+}
+
+// Choreography assigns movers to parts of a beatmap. It's loaded from choreography/<beatmap MD5>.json
+type Choreography struct {
+	Sections []*ChoreographySection `json:"sections"`
+}
+
+// ChoreographySection switches cursors to Mover between From and To (whole map if not set).
+// If Kiai is true, only kiai times in that range are used. Settings override mover's settings, Cursors limits the section to given cursor indices.
+type ChoreographySection struct {
+	From     choreographyTime `json:"from"`
+	To       choreographyTime `json:"to"`
+	Kiai     bool             `json:"kiai"`
+	Mover    string           `json:"mover"`
+	Settings json.RawMessage  `json:"settings"`
+	Cursors  []int            `json:"cursors"`
+}
+
+// choreographyTime is a time in milliseconds, given as a number or a "mm:ss.mmm" string
+type choreographyTime float64
+
+func (t *choreographyTime) UnmarshalJSON(data []byte) error {
+	var ms float64
+	if err := json.Unmarshal(data, &ms); err == nil {
+		*t = choreographyTime(ms)
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	parts := choreographyTimeRegex.FindStringSubmatch(str)
+	if parts == nil {
+		return fmt.Errorf("invalid time \"%s\", expected mm:ss.mmm", str)
+	}
+
+	minutes, _ := strconv.Atoi(parts[1])
+	seconds, _ := strconv.Atoi(parts[2])
+
+	fraction := parts[4]
+	if parts[3] == "." {
+		fraction += strings.Repeat("0", 3-len(fraction))
+	}
+
+	millis, _ := strconv.Atoi(fraction)
+
+	*t = choreographyTime(minutes*60000 + seconds*1000 + millis)
+
+	return nil
+}
+
+// LoadChoreography loads choreography of the beatmap, returns nil if it doesn't exist or is invalid
+func LoadChoreography(bMap *beatmap.BeatMap) *Choreography {
+	path := filepath.Join(env.DataDir(), "choreography", strings.ToLower(bMap.MD5)+".json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println("Choreography: Failed to read choreography:", err)
+		}
+
+		return nil
+	}
+
+	var choreography Choreography
+
+	if err = json.Unmarshal(data, &choreography); err != nil {
+		log.Println(fmt.Sprintf("Choreography: Failed to parse \"%s\": %s", path, err))
+		return nil
+	}
+
+	log.Println(fmt.Sprintf("Choreography: Loaded %d sections from \"%s\"", len(choreography.Sections), path))
+
+	return &choreography
+}
+
+// GetMoverSections creates movers of sections used by the given cursor
+func (c *Choreography) GetMoverSections(bMap *beatmap.BeatMap, cursor int) (sections []schedulers.MoverSection) {
+	for i, s := range c.Sections {
+		if len(s.Cursors) > 0 && !slices.Contains(s.Cursors, cursor) {
+			continue
+		}
+
+		moverCtor, mName := movers.GetMoverCtorByName(s.Mover)
+		if mName != strings.ToLower(s.Mover) {
+			log.Println(fmt.Sprintf("Choreography: Unknown mover \"%s\" in section %d, using %s", s.Mover, i+1, mName))
+		}
+
+		var config any
+
+		if len(s.Settings) > 0 {
+			var err error
+			if config, err = newMoverSettings(mName, cursor, s.Settings); err != nil {
+				log.Println(fmt.Sprintf("Choreography: Invalid settings in section %d: %s", i+1, err))
+			}
+		}
+
+		mover := moverCtor()
+
+		for _, r := range s.timeRanges(bMap) {
+			sections = append(sections, schedulers.MoverSection{
+				StartTime: r[0],
+				EndTime:   r[1],
+				Mover:     mover,
+				ID:        cursor,
+				Settings:  config,
+			})
+		}
+	}
+
+	return
+}
+
+func (s *ChoreographySection) timeRanges(bMap *beatmap.BeatMap) (ranges [][2]float64) {
+	from, to := float64(s.From), float64(s.To)
+	if to <= 0 {
+		to = math.Inf(1)
+	}
+
+	if !s.Kiai {
+		return [][2]float64{{from, to}}
+	}
+
+	kiaiStart := math.NaN()
+
+	for _, p := range bMap.Timings.GetPoints() {
+		if p.Kiai && math.IsNaN(kiaiStart) {
+			kiaiStart = p.Time
+		} else if !p.Kiai && !math.IsNaN(kiaiStart) {
+			ranges = append(ranges, [2]float64{kiaiStart, p.Time})
+			kiaiStart = math.NaN()
+		}
+	}
+
+	if !math.IsNaN(kiaiStart) {
+		ranges = append(ranges, [2]float64{kiaiStart, math.Inf(1)})
+	}
+
+	// Clip kiai times to the section
+	clipped := ranges[:0]
+
+	for _, r := range ranges {
+		start, end := max(r[0], from), min(r[1], to)
+
+		if start < end {
+			clipped = append(clipped, [2]float64{start, end})
+		}
+	}
+
+	return clipped
+}
+
+// newMoverSettings creates a copy of mover's settings selected by id with overrides applied.
+// Global settings are left untouched, the copy is given to the mover with SetConfig.
+func newMoverSettings(mover string, id int, overrides json.RawMessage) (any, error) {
+	fieldName, ok := moverSettingsFields[mover]
+	if !ok {
+		return nil, fmt.Errorf("%s mover doesn't have settings", mover)
+	}
+
+	field := reflect.ValueOf(settings.CursorDance.MoverSettings).Elem().FieldByName(fieldName)
+
+	if field.Len() == 0 {
+		return nil, fmt.Errorf("%s mover doesn't have base settings", mover)
+	}
+
+	base, err := json.Marshal(field.Index(id % field.Len()).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	entry := reflect.New(field.Type().Elem().Elem())
+
+	if err = json.Unmarshal(base, entry.Interface()); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(overrides))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(entry.Interface()); err != nil {
+		return nil, err
+	}
+
+	return entry.Interface(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/wieku/danser-go/app/dance/schedulers"
 	"github.com/wieku/danser-go/app/dance/spinners"
 	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
 	"sort"
 	"strings"
@@ -19,6 +20,15 @@ type Controller interface {
 	GetCursors() []*graphics.Cursor
 }
 
+// ScoredController is a Controller whose cursors are judged by the ruleset, it's what KnockoutOverlay displays
+type ScoredController interface {
+	Controller
+	GetReplays() []RpData
+	GetRuleset() *osu.OsuRuleSet
+	GetBeatMap() *beatmap.BeatMap
+	GetClick(player, key int) bool
+}
+
 type GenericController struct {
 	bMap       *beatmap.BeatMap
 	cursors    []*graphics.Cursor
@@ -34,23 +44,33 @@ func (controller *GenericController) SetBeatMap(beatMap *beatmap.BeatMap) {
 }
 
 func (controller *GenericController) InitCursors() {
+	// Movers of previous cursors are replaced
+	for _, s := range controller.schedulers {
+		if gs, ok := s.(*schedulers.GenericScheduler); ok {
+			gs.Close()
+		}
+	}
+
 	controller.cursors = make([]*graphics.Cursor, settings.TAG)
 	controller.schedulers = make([]schedulers.Scheduler, settings.TAG)
 
 	counter := make(map[string]int)
 
+	choreography := LoadChoreography(controller.bMap)
+
 	// Mover initialization
 	for i := range controller.cursors {
 		controller.cursors[i] = graphics.NewCursor()
 
-		mover := "flower"
-		if len(settings.CursorDance.Movers) > 0 {
-			mover = strings.ToLower(settings.CursorDance.Movers[i%len(settings.CursorDance.Movers)].Mover)
-		}
+		moverCtor, mName := movers.GetMoverCtorByName(cursorMover(i))
 
-		moverCtor, mName := movers.GetMoverCtorByName(mover)
+		scheduler := schedulers.NewGenericScheduler(moverCtor, i, counter[mName]).(*schedulers.GenericScheduler)
 
-		controller.schedulers[i] = schedulers.NewGenericScheduler(moverCtor, i, counter[mName])
+		if choreography != nil {
+			scheduler.SetMoverSections(choreography.GetMoverSections(controller.bMap, i))
+		}
+
+		controller.schedulers[i] = scheduler
 
 		counter[mName]++
 	}
@@ -138,6 +158,12 @@ func (controller *GenericController) InitCursors() {
 		}
 	}
 
+	var assignment []int
+
+	if settings.TAG > 1 && !settings.CursorDance.ComboTag && !settings.CursorDance.Battle && settings.CursorDance.TAGAssignment != AssignRoundRobin {
+		assignment = controller.assignTAGObjects(queue)
+	}
+
 	// If DoSpinnersTogether is true with tag mode, allow all tag cursors to spin the same spinner with different movers
 	for j, o := range queue {
 		_, isSpinner := o.(*objects.Spinner)
@@ -149,6 +175,8 @@ func (controller *GenericController) InitCursors() {
 		} else if settings.CursorDance.ComboTag {
 			i := int(o.GetComboSet()) % settings.TAG
 			queues[i].hitObjects = append(queues[i].hitObjects, o)
+		} else if assignment != nil {
+			queues[assignment[j]].hitObjects = append(queues[assignment[j]].hitObjects, o)
 		} else {
 			i := j % settings.TAG
 			queues[i].hitObjects = append(queues[i].hitObjects, o)
@@ -166,6 +194,31 @@ func (controller *GenericController) InitCursors() {
 	}
 }
 
+// assignTAGObjects assigns objects to cursors using selected strategy, spinners done together are left out
+func (controller *GenericController) assignTAGObjects(queue []objects.IHitObject) []int {
+	assignable := make([]objects.IHitObject, 0, len(queue))
+	indices := make([]int, 0, len(queue))
+
+	for j, o := range queue {
+		if _, isSpinner := o.(*objects.Spinner); isSpinner && settings.CursorDance.DoSpinnersTogether {
+			continue
+		}
+
+		assignable = append(assignable, o)
+		indices = append(indices, j)
+	}
+
+	assigned := assignObjects(assignable, controller.bMap.Diff, settings.TAG, settings.CursorDance.TAGAssignment)
+
+	assignment := make([]int, len(queue))
+
+	for k, j := range indices {
+		assignment[j] = assigned[k]
+	}
+
+	return assignment
+}
+
 func (controller *GenericController) Update(time float64, delta float64) {
 	for i := range controller.cursors {
 		controller.schedulers[i].Update(time)
@@ -179,3 +232,12 @@ func (controller *GenericController) Update(time float64, delta float64) {
 func (controller *GenericController) GetCursors() []*graphics.Cursor {
 	return controller.cursors
 }
+
+// cursorMover returns the name of the mover set for i-th cursor
+func cursorMover(i int) string {
+	if len(settings.CursorDance.Movers) == 0 {
+		return "flower"
+	}
+
+	return strings.ToLower(settings.CursorDance.Movers[i%len(settings.CursorDance.Movers)].Mover)
+}
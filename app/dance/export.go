@@ -0,0 +1,102 @@
+package dance
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/dance/movers"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+)
+
+// ExportDance runs cursordance without drawing anything and saves the path of every cursor as a replay.
+// Cursors are judged at recorded frames, so hit counts stored in replays match what -replay would show.
+// In TAG mode objects are split between cursors, so they are not judged and replays are saved without results.
+// Beatmap has to have its objects parsed and mods applied beforehand.
+func ExportDance(beatMap *beatmap.BeatMap) (paths []string) {
+	controller := NewGenericController().(*GenericController)
+	controller.SetBeatMap(beatMap)
+	controller.InitCursors()
+
+	cursors := controller.GetCursors()
+
+	diffs := make([]*difficulty.Difficulty, len(cursors))
+	recorders := make([]*ReplayRecorder, len(cursors))
+
+	for i, cursor := range cursors {
+		_, mName := movers.GetMoverCtorByName(cursorMover(i))
+
+		cursor.Name = fmt.Sprintf("%s (%s)", settings.Knockout.DanserName, mName)
+		if len(cursors) > 1 {
+			cursor.Name += fmt.Sprintf(" #%d", i+1)
+		}
+
+		diffs[i] = beatMap.Diff.Clone()
+		recorders[i] = NewReplayRecorder(cursor)
+	}
+
+	// Ruleset judges every object for every cursor, which is true only if each cursor dances the whole map
+	judged := len(cursors) == 1 || settings.CursorDance.Battle
+
+	var ruleset *osu.OsuRuleSet
+
+	if judged {
+		ruleset = osu.NewOsuRuleset(beatMap, cursors, diffs)
+	} else {
+		log.Println("Objects are split between TAG cursors, replays will be saved without results")
+	}
+
+	startTime := min(-1000, beatMap.HitObjects[0].GetStartTime()-beatMap.Diff.Preempt)
+	endTime := beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime() + float64(beatMap.Diff.Hit50) + 1000
+
+	lastTime := startTime - 1
+
+	for t := startTime; t <= endTime; t++ {
+		controller.Update(t, t-lastTime)
+
+		for i, cursor := range cursors {
+			cursor.IsReplayFrame = recorders[i].Update(int64(t))
+		}
+
+		if !judged {
+			lastTime = t
+			continue
+		}
+
+		for _, cursor := range cursors {
+			ruleset.UpdateClickFor(cursor, int64(t))
+			ruleset.UpdateNormalFor(cursor, int64(t), false)
+			ruleset.UpdatePostFor(cursor, int64(t), false)
+		}
+
+		ruleset.Update(int64(t))
+
+		for i, cursor := range cursors {
+			recorders[i].UpdateLife(int64(t), ruleset.GetHP(cursor))
+		}
+
+		lastTime = t
+	}
+
+	for i, cursor := range cursors {
+		var score osu.Score
+		if judged {
+			score = ruleset.GetScore(cursor)
+		}
+
+		replay := recorders[i].Build(beatMap, diffs[i].Mods, score)
+
+		path, err := SaveReplay(beatMap, replay)
+		if err != nil {
+			log.Println(fmt.Sprintf("Failed to save replay of %s: %s", cursor.Name, err))
+			continue
+		}
+
+		log.Println(fmt.Sprintf("Replay of %s saved to: %s", cursor.Name, path))
+
+		paths = append(paths, path)
+	}
+
+	return
+}
@@ -0,0 +1,185 @@
+package human
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ProfileSamples is the number of intervals movement's progress is sampled at
+const ProfileSamples = 16
+
+var models = make(map[string]*Model)
+var modelsMutex sync.Mutex
+
+// Stat is a normal distribution of a measured value
+type Stat struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+}
+
+func (s Stat) Sample(rng *rand.Rand) float64 {
+	return s.Mean + rng.NormFloat64()*s.StdDev
+}
+
+// Model describes how a player moves the cursor and presses keys, built from replays by Trainer
+type Model struct {
+	Replays   int `json:"replays"`
+	Movements int `json:"movements"`
+
+	// Progress towards the next object at ProfileSamples+1 evenly spaced points of movement's time
+	Progress []float64 `json:"progress"`
+
+	// Largest distance from the straight line between objects, relative to the distance
+	Curvature Stat `json:"curvature"`
+
+	// Distance the cursor goes past the object after hitting it, relative to the distance between objects
+	Overshoot Stat `json:"overshoot"`
+
+	// Time after the hit when the cursor is furthest past the object
+	OvershootTime Stat `json:"overshootTime"`
+
+	// Distance from object's center when it's hit, relative to circle radius
+	AimError Stat `json:"aimError"`
+
+	// Difference between hit time and object's time
+	HitOffset Stat `json:"hitOffset"`
+
+	// Time a key is held when hitting a circle
+	PressDuration Stat `json:"pressDuration"`
+
+	// Objects closer than this are alternated, further ones are single tapped
+	SingleTapThreshold float64 `json:"singleTapThreshold"`
+}
+
+// DefaultModel returns a model used when no model was trained
+func DefaultModel() *Model {
+	progress := make([]float64, ProfileSamples+1)
+
+	for i := range progress {
+		t := float64(i) / ProfileSamples
+		progress[i] = t * t * t * (10 - 15*t + 6*t*t) // minimum jerk trajectory
+	}
+
+	return &Model{
+		Progress:           progress,
+		Curvature:          Stat{Mean: 0.05, StdDev: 0.04},
+		Overshoot:          Stat{Mean: 0.03, StdDev: 0.04},
+		OvershootTime:      Stat{Mean: 40, StdDev: 15},
+		AimError:           Stat{Mean: 0.3, StdDev: 0.15},
+		HitOffset:          Stat{Mean: -3, StdDev: 12},
+		PressDuration:      Stat{Mean: 70, StdDev: 20},
+		SingleTapThreshold: 140,
+	}
+}
+
+// ProgressAt returns normalized progress of the movement at normalized time t
+func (model *Model) ProgressAt(t float64) float64 {
+	p := model.Progress
+
+	if len(p) < 2 || p[len(p)-1]-p[0] < 0.1 {
+		return t
+	}
+
+	t = mutils.Clamp(t, 0, 1) * float64(len(p)-1)
+
+	i := min(int(t), len(p)-2)
+
+	value := p[i] + (p[i+1]-p[i])*(t-float64(i))
+
+	return (value - p[0]) / (p[len(p)-1] - p[0])
+}
+
+func (model *Model) Save(path string) error {
+	data, err := json.MarshalIndent(model, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// GetModel loads the model from path relative to danser's directory, falls back to DefaultModel if it can't be loaded
+func GetModel(path string) *Model {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(env.DataDir(), path)
+	}
+
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+
+	if model, ok := models[path]; ok {
+		return model
+	}
+
+	model, err := loadModel(path)
+	if err != nil {
+		log.Println("HumanModel: Can't load the model, using the default one:", err)
+		model = DefaultModel()
+	}
+
+	models[path] = model
+
+	return model
+}
+
+func loadModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := DefaultModel()
+
+	if err = json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+
+	if len(model.Progress) != ProfileSamples+1 {
+		return nil, fmt.Errorf("%s: expected %d progress samples, got %d", path, ProfileSamples+1, len(model.Progress))
+	}
+
+	return model, nil
+}
+
+// DefaultModelPath returns the path models are saved to by default
+func DefaultModelPath() string {
+	return filepath.Join(env.DataDir(), "human.json")
+}
+
+// stat accumulates values to compute their Stat
+type stat struct {
+	n     int
+	sum   float64
+	sumSq float64
+}
+
+func (s *stat) add(v float64) {
+	s.n++
+	s.sum += v
+	s.sumSq += v * v
+}
+
+func (s *stat) result(fallback Stat) Stat {
+	if s.n < 10 {
+		return fallback
+	}
+
+	mean := s.sum / float64(s.n)
+
+	return Stat{
+		Mean:   mean,
+		StdDev: math.Sqrt(max(0, s.sumSq/float64(s.n)-mean*mean)),
+	}
+}
@@ -0,0 +1,346 @@
+package human
+
+import (
+	"errors"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+	"math"
+	"sort"
+)
+
+const (
+	// Movements shorter than that are too small to tell anything about aiming
+	minMovementRadii = 2
+	maxMovementTime  = 1000
+
+	// Time after the hit searched for overshoot
+	overshootWindow = 200
+
+	tapBucketSize = 10
+	tapBuckets    = 50
+	minTapSamples = 5
+)
+
+type frame struct {
+	time  float64
+	pos   vector.Vector2f
+	left  bool
+	right bool
+}
+
+type press struct {
+	time    float64
+	release float64
+	pos     vector.Vector2f
+	right   bool
+}
+
+// Trainer builds a Model from replays
+type Trainer struct {
+	replays   int
+	movements int
+
+	progress [ProfileSamples + 1]float64
+
+	curvature     stat
+	overshoot     stat
+	overshootTime stat
+	aimError      stat
+	hitOffset     stat
+	pressDuration stat
+
+	tapTotal     [tapBuckets]int
+	tapAlternate [tapBuckets]int
+}
+
+func NewTrainer() *Trainer {
+	return &Trainer{}
+}
+
+// AddReplay measures the replay. Beatmap has to have objects parsed with replay's mods applied.
+func (trainer *Trainer) AddReplay(bMap *beatmap.BeatMap, replay *rplpa.Replay) error {
+	if replay.PlayMode != 0 {
+		return errors.New("only osu!standard replays are supported")
+	}
+
+	frames := getFrames(replay)
+	if len(frames) < 2 {
+		return errors.New("replay is missing input data")
+	}
+
+	presses := getPresses(frames)
+
+	diff := bMap.Diff
+	radius := float32(diff.CircleRadius)
+	window := float64(diff.Hit50)
+
+	type hit struct {
+		object objects.IHitObject
+		press  *press
+	}
+
+	hits := make([]hit, 0, len(bMap.HitObjects))
+
+	pressIndex := 0
+
+	for _, o := range bMap.HitObjects {
+		if o.GetType()&objects.SPINNER > 0 {
+			hits = append(hits, hit{object: o})
+			continue
+		}
+
+		for pressIndex < len(presses) && presses[pressIndex].time < o.GetStartTime()-window {
+			pressIndex++
+		}
+
+		var matched *press
+
+		if pressIndex < len(presses) {
+			p := &presses[pressIndex]
+
+			if p.time <= o.GetStartTime()+window && p.pos.Dst(o.GetStackedStartPositionMod(diff)) <= radius*1.2 {
+				matched = p
+				pressIndex++
+			}
+		}
+
+		hits = append(hits, hit{object: o, press: matched})
+	}
+
+	for i, h := range hits {
+		if h.press == nil {
+			continue
+		}
+
+		pos := h.object.GetStackedStartPositionMod(diff)
+
+		trainer.hitOffset.add(h.press.time - h.object.GetStartTime())
+		trainer.aimError.add(float64(h.press.pos.Dst(pos) / radius))
+
+		_, isCircle := h.object.(*objects.Circle)
+
+		if isCircle && h.press.release > h.press.time {
+			trainer.pressDuration.add(min(h.press.release-h.press.time, 500))
+		}
+
+		if i == 0 || hits[i-1].press == nil {
+			continue
+		}
+
+		prev := hits[i-1]
+
+		if bucket := int((h.object.GetStartTime() - prev.object.GetStartTime()) / tapBucketSize); bucket < tapBuckets {
+			trainer.tapTotal[bucket]++
+
+			if prev.press.right != h.press.right {
+				trainer.tapAlternate[bucket]++
+			}
+		}
+
+		if _, ok := prev.object.(*objects.Circle); ok {
+			overshootEnd := h.press.time + overshootWindow
+
+			// Second half of the time to the next hit is most likely already a movement to the next object
+			if i+1 < len(hits) && hits[i+1].press != nil {
+				overshootEnd = min(overshootEnd, (h.press.time+hits[i+1].press.time)/2)
+			}
+
+			trainer.addMovement(frames, prev.press.time, h.press.time, overshootEnd, pos, radius)
+		}
+	}
+
+	trainer.replays++
+
+	return nil
+}
+
+// addMovement measures cursor's path from the hit at tA to the object at pos hit at tB
+func (trainer *Trainer) addMovement(frames []frame, tA, tB, overshootEnd float64, pos vector.Vector2f, radius float32) {
+	duration := tB - tA
+	if duration < 50 || duration > maxMovementTime {
+		return
+	}
+
+	start := positionAt(frames, tA)
+
+	dist := start.Dst(pos)
+	if dist < radius*minMovementRadii {
+		return
+	}
+
+	dir := pos.Sub(start).Scl(1 / dist)
+	normal := vector.NewVec2f(-dir.Y, dir.X)
+
+	for k := 0; k <= ProfileSamples; k++ {
+		p := positionAt(frames, tA+duration*float64(k)/ProfileSamples).Sub(start)
+
+		trainer.progress[k] += float64(p.Dot(dir) / dist)
+	}
+
+	var deviation float32
+
+	for k := 1; k < ProfileSamples; k++ {
+		p := positionAt(frames, tA+duration*float64(k)/ProfileSamples).Sub(start)
+
+		deviation = max(deviation, float32(math.Abs(float64(p.Dot(normal)/dist))))
+	}
+
+	trainer.curvature.add(float64(deviation))
+
+	var overshoot float32
+	var overshootTime float64
+
+	for _, f := range frames[searchFrame(frames, tB):] {
+		if f.time > overshootEnd {
+			break
+		}
+
+		if o := (f.pos.Sub(start).Dot(dir) - dist) / dist; o > overshoot {
+			overshoot = o
+			overshootTime = f.time - tB
+		}
+	}
+
+	trainer.overshoot.add(float64(overshoot))
+
+	if overshoot > 0 {
+		trainer.overshootTime.add(overshootTime)
+	}
+
+	trainer.movements++
+}
+
+// Model returns the model built from added replays, values with too few samples are taken from DefaultModel
+func (trainer *Trainer) Model() *Model {
+	model := DefaultModel()
+
+	model.Replays = trainer.replays
+	model.Movements = trainer.movements
+
+	if trainer.movements >= 10 {
+		for k := range model.Progress {
+			model.Progress[k] = trainer.progress[k] / float64(trainer.movements)
+		}
+	}
+
+	model.Curvature = trainer.curvature.result(model.Curvature)
+	model.Overshoot = trainer.overshoot.result(model.Overshoot)
+	model.OvershootTime = trainer.overshootTime.result(model.OvershootTime)
+	model.AimError = trainer.aimError.result(model.AimError)
+	model.HitOffset = trainer.hitOffset.result(model.HitOffset)
+	model.PressDuration = trainer.pressDuration.result(model.PressDuration)
+
+	// Threshold is the end of the last interval that's mostly alternated, before intervals become mostly single tapped
+	threshold := 0.0
+
+	for b := 0; b < tapBuckets; b++ {
+		if trainer.tapTotal[b] < minTapSamples {
+			continue
+		}
+
+		if trainer.tapAlternate[b]*2 < trainer.tapTotal[b] {
+			break
+		}
+
+		threshold = float64(b+1) * tapBucketSize
+	}
+
+	if threshold > 0 {
+		model.SingleTapThreshold = threshold
+	}
+
+	return model
+}
+
+func getFrames(replay *rplpa.Replay) []frame {
+	frames := make([]frame, 0, len(replay.ReplayData))
+
+	time := 0.0
+
+	for _, d := range replay.ReplayData {
+		if d.Time == -12345 { // RNG seed
+			continue
+		}
+
+		time += d.Time
+
+		f := frame{
+			time: time,
+			pos:  vector.NewVec2f(float32(d.MouseX), float32(d.MouseY)),
+		}
+
+		if d.KeyPressed != nil {
+			f.left = d.KeyPressed.LeftClick
+			f.right = d.KeyPressed.RightClick
+		}
+
+		frames = append(frames, f)
+	}
+
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].time < frames[j].time
+	})
+
+	return frames
+}
+
+func getPresses(frames []frame) (presses []press) {
+	var wasLeft, wasRight bool
+
+	for _, f := range frames {
+		if f.left && !wasLeft {
+			presses = append(presses, press{time: f.time, release: math.Inf(1), pos: f.pos})
+		}
+
+		if f.right && !wasRight {
+			presses = append(presses, press{time: f.time, release: math.Inf(1), pos: f.pos, right: true})
+		}
+
+		wasLeft, wasRight = f.left, f.right
+	}
+
+	// Releases are found separately, presses slice could be reallocated while appending
+	for i := range presses {
+		p := &presses[i]
+
+		for _, f := range frames[searchFrame(frames, p.time):] {
+			if f.time <= p.time {
+				continue
+			}
+
+			if (!p.right && !f.left) || (p.right && !f.right) {
+				p.release = f.time
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// searchFrame returns index of the last frame at or before time
+func searchFrame(frames []frame, time float64) int {
+	i := sort.Search(len(frames), func(i int) bool {
+		return frames[i].time > time
+	})
+
+	return max(0, i-1)
+}
+
+func positionAt(frames []frame, time float64) vector.Vector2f {
+	i := searchFrame(frames, time)
+
+	if i+1 >= len(frames) || frames[i].time >= time {
+		return frames[i].pos
+	}
+
+	a, b := frames[i], frames[i+1]
+
+	if b.time <= a.time {
+		return b.pos
+	}
+
+	return a.pos.Lerp(b.pos, float32((time-a.time)/(b.time-a.time)))
+}
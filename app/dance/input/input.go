@@ -19,11 +19,13 @@ type NaturalInputProcessor struct {
 	previousEnd    float64
 	releaseLeftAt  float64
 	releaseRightAt float64
-	mover          movers.MultiPointMover
+	mover          func() movers.MultiPointMover
 	speed          float64
 }
 
-func NewNaturalInputProcessor(objs []objects.IHitObject, cursor *graphics.Cursor, mover movers.MultiPointMover, speed float64) *NaturalInputProcessor {
+// NewNaturalInputProcessor creates input processor that clicks objects at times given by the mover currently used by the scheduler,
+// so movers that change object times (e.g. human) are followed also when they're used only in some sections
+func NewNaturalInputProcessor(objs []objects.IHitObject, cursor *graphics.Cursor, mover func() movers.MultiPointMover, speed float64) *NaturalInputProcessor {
 	processor := &NaturalInputProcessor{
 		mover:          mover,
 		cursor:         cursor,
@@ -40,6 +42,8 @@ func NewNaturalInputProcessor(objs []objects.IHitObject, cursor *graphics.Cursor
 
 func (processor *NaturalInputProcessor) Update(time float64) {
 	if len(processor.queue) > 0 {
+		mover := processor.mover()
+
 		for i := 0; i < len(processor.queue); i++ {
 			g := processor.queue[i]
 
@@ -48,8 +52,8 @@ func (processor *NaturalInputProcessor) Update(time float64) {
 				isDoubleClick = true
 			}
 
-			gStartTime := processor.mover.GetObjectsStartTime(g)
-			gEndTime := processor.mover.GetObjectsEndTime(g)
+			gStartTime := mover.GetObjectsStartTime(g)
+			gEndTime := mover.GetObjectsEndTime(g)
 
 			if gStartTime > time {
 				break
@@ -59,7 +63,15 @@ func (processor *NaturalInputProcessor) Update(time float64) {
 				startTime := gStartTime
 				endTime := gEndTime
 
-				releaseAt := endTime + 50.0
+				releaseDelay := 50.0
+				threshold := float64(singleTapThreshold)
+
+				if style, ok := mover.(movers.InputStyle); ok {
+					releaseDelay = style.GetReleaseDelay()
+					threshold = style.GetSingleTapThreshold()
+				}
+
+				releaseAt := endTime + releaseDelay
 
 				if i+1 < len(processor.queue) {
 					j := i + 1
@@ -67,7 +79,7 @@ func (processor *NaturalInputProcessor) Update(time float64) {
 						// Prolong the click if slider tick is the next object
 						if cC, ok := processor.queue[j].(*objects.Circle); ok && cC.SliderPoint && !cC.SliderPointStart {
 							endTime = cC.GetEndTime()
-							releaseAt = endTime + 50.0
+							releaseAt = endTime + releaseDelay
 						} else {
 							break
 						}
@@ -88,13 +100,13 @@ func (processor *NaturalInputProcessor) Update(time float64) {
 						}
 
 						if obj != nil {
-							nTime := processor.mover.GetObjectsStartTime(obj)
+							nTime := mover.GetObjectsStartTime(obj)
 							releaseAt = mutils.Clamp(nTime-1, endTime+1, releaseAt)
 						}
 					}
 				}
 
-				shouldBeLeft := !processor.wasLeftBefore && startTime-processor.previousEnd < singleTapThreshold*processor.speed
+				shouldBeLeft := !processor.wasLeftBefore && startTime-processor.previousEnd < threshold*processor.speed
 
 				if isDoubleClick {
 					processor.releaseLeftAt = releaseAt
@@ -34,7 +34,7 @@ func (mover *AngleOffsetMover) Reset(diff *difficulty.Difficulty, id int) {
 }
 
 func (mover *AngleOffsetMover) SetObjects(objs []objects.IHitObject) int {
-	config := settings.CursorDance.MoverSettings.Flower[mover.id%len(settings.CursorDance.MoverSettings.Flower)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Flower)
 
 	start, end := objs[0], objs[1]
 
@@ -33,7 +33,7 @@ func (mover *BezierMover) Reset(diff *difficulty.Difficulty, id int) {
 }
 
 func (mover *BezierMover) SetObjects(objs []objects.IHitObject) int {
-	config := settings.CursorDance.MoverSettings.Bezier[mover.id%len(settings.CursorDance.MoverSettings.Bezier)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Bezier)
 
 	start, end := objs[0], objs[1]
 
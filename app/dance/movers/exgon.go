@@ -33,7 +33,7 @@ func (mover *ExGonMover) Reset(diff *difficulty.Difficulty, id int) {
 }
 
 func (mover *ExGonMover) SetObjects(objs []objects.IHitObject) int {
-	config := settings.CursorDance.MoverSettings.ExGon[mover.id%len(settings.CursorDance.MoverSettings.ExGon)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.ExGon)
 	mover.delay = float64(config.Delay)
 
 	if !mover.wasFirst {
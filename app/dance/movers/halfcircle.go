@@ -29,7 +29,7 @@ func (mover *HalfCircleMover) Reset(diff *difficulty.Difficulty, id int) {
 }
 
 func (mover *HalfCircleMover) SetObjects(objs []objects.IHitObject) int {
-	config := settings.CursorDance.MoverSettings.HalfCircle[mover.id%len(settings.CursorDance.MoverSettings.HalfCircle)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.HalfCircle)
 
 	start, end := objs[0], objs[1]
 
@@ -0,0 +1,230 @@
+package movers
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/dance/human"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+	"math/rand"
+)
+
+// Same limit as used when measuring movements in replays
+const humanMaxMovementTime = 1000
+
+// HumanMover imitates a player using a model built from their replays: cursor's velocity profile, curvature, overshoot,
+// aim error and hit offsets. It implements InputStyle, so keys are held and alternated like in the replays.
+type HumanMover struct {
+	*basicMover
+
+	model *human.Model
+	seed  int64
+
+	// Samples are drawn per object so that they don't depend on the order in which mover and input processor query them
+	samples map[humanObjectKey]*humanSamples
+
+	// Separate generator for key releases, input processor asks for them at frame-dependent times
+	releaseRng *rand.Rand
+
+	startPos  vector.Vector2f
+	endPos    vector.Vector2f
+	moveStart float64
+	curvature float32
+
+	// Overshoot of the previous movement, it happens at the beginning of the current one
+	overshootDir  vector.Vector2f
+	overshoot     float32
+	overshootTime float64
+
+	nextOvershootDir  vector.Vector2f
+	nextOvershoot     float32
+	nextOvershootTime float64
+}
+
+// DummyCircles created by the scheduler share HitObjectID, so start time is a part of the key
+type humanObjectKey struct {
+	id   int64
+	time float64
+}
+
+type humanSamples struct {
+	hitOffset     float64
+	aimOffset     vector.Vector2f
+	curvature     float32
+	overshoot     float64
+	overshootTime float64
+}
+
+func NewHumanMover() MultiPointMover {
+	return &HumanMover{basicMover: &basicMover{}}
+}
+
+func (mover *HumanMover) Reset(diff *difficulty.Difficulty, id int) {
+	mover.basicMover.Reset(diff, id)
+
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Human)
+
+	mover.model = human.GetModel(config.Model)
+	mover.seed = int64(id)<<16 | int64(id%len(settings.CursorDance.MoverSettings.Human))
+
+	mover.samples = make(map[humanObjectKey]*humanSamples)
+	mover.releaseRng = rand.New(rand.NewSource(mover.seed))
+
+	mover.overshoot = 0
+	mover.nextOvershoot = 0
+}
+
+func (mover *HumanMover) SetObjects(objs []objects.IHitObject) int {
+	start, end := objs[0], objs[1]
+
+	mover.startTime = mover.GetObjectsEndTime(start)
+	mover.endTime = mover.GetObjectsStartTime(end)
+
+	mover.startPos = mover.GetObjectsEndPosition(start)
+	mover.endPos = mover.GetObjectsStartPosition(end)
+
+	mover.moveStart = max(mover.startTime, mover.endTime-humanMaxMovementTime)
+
+	dist := mover.startPos.Dst(mover.endPos)
+
+	// Movement is shaped by samples of the object it ends on
+	samples := mover.getSamples(end)
+
+	mover.curvature = samples.curvature
+
+	// Overshoot can happen only after hitting a circle, cursor has to follow sliders and spinners
+	mover.overshoot = 0
+
+	if _, ok := start.(*objects.Circle); ok && mover.nextOvershoot > 0 {
+		mover.overshoot = mover.nextOvershoot
+		mover.overshootDir = mover.nextOvershootDir
+		mover.overshootTime = min(mover.nextOvershootTime, (mover.endTime-mover.startTime)/4)
+	}
+
+	mover.nextOvershoot = 0
+
+	if dist > 0 {
+		mover.nextOvershootDir = mover.endPos.Sub(mover.startPos).Scl(1 / dist)
+		mover.nextOvershoot = mutils.Clamp(float32(samples.overshoot)*dist, 0, float32(mover.diff.CircleRadius)*1.5)
+		mover.nextOvershootTime = samples.overshootTime
+	}
+
+	return 2
+}
+
+func (mover *HumanMover) Update(time float64) vector.Vector2f {
+	pos := mover.startPos
+
+	if time > mover.moveStart && mover.endTime > mover.moveStart {
+		t := mutils.Clamp((time-mover.moveStart)/(mover.endTime-mover.moveStart), 0, 1)
+
+		pos = mover.startPos.Lerp(mover.endPos, float32(mover.model.ProgressAt(t)))
+
+		diff := mover.endPos.Sub(mover.startPos)
+		normal := vector.NewVec2f(-diff.Y, diff.X)
+
+		pos = pos.Add(normal.Scl(mover.curvature * 4 * float32(t*(1-t))))
+	}
+
+	if mover.overshoot > 0 && time < mover.startTime+2*mover.overshootTime {
+		u := float32((time - mover.startTime) / (2 * mover.overshootTime))
+
+		pos = pos.Add(mover.overshootDir.Scl(mover.overshoot * math32.Sin(u*math32.Pi)))
+	}
+
+	return pos
+}
+
+func (mover *HumanMover) GetObjectsStartTime(object objects.IHitObject) float64 {
+	return object.GetStartTime() + mover.getHitOffset(object)
+}
+
+func (mover *HumanMover) GetObjectsEndTime(object objects.IHitObject) float64 {
+	if _, ok := object.(*objects.Circle); ok {
+		return object.GetEndTime() + mover.getHitOffset(object)
+	}
+
+	return object.GetEndTime()
+}
+
+func (mover *HumanMover) GetObjectsStartPosition(object objects.IHitObject) vector.Vector2f {
+	return object.GetStackedStartPositionMod(mover.diff).Add(mover.getAimOffset(object))
+}
+
+func (mover *HumanMover) GetObjectsEndPosition(object objects.IHitObject) vector.Vector2f {
+	return object.GetStackedEndPositionMod(mover.diff).Add(mover.getAimOffset(object))
+}
+
+func (mover *HumanMover) GetReleaseDelay() float64 {
+	return mutils.Clamp(mover.model.PressDuration.Sample(mover.releaseRng), 20, 300)
+}
+
+func (mover *HumanMover) GetSingleTapThreshold() float64 {
+	return mover.model.SingleTapThreshold
+}
+
+// getHitOffset returns how late the object is hit, only circles and slider heads are affected
+func (mover *HumanMover) getHitOffset(object objects.IHitObject) float64 {
+	if !isHumanHittable(object) {
+		return 0
+	}
+
+	return mover.getSamples(object).hitOffset
+}
+
+// getAimOffset returns how far from the center the circle is hit, sliders are followed precisely
+func (mover *HumanMover) getAimOffset(object objects.IHitObject) vector.Vector2f {
+	if c, ok := object.(*objects.Circle); !ok || !isHumanHittable(c) {
+		return vector.NewVec2f(0, 0)
+	}
+
+	return mover.getSamples(object).aimOffset
+}
+
+// getSamples returns object's samples, they are drawn from a generator seeded by mover's seed and the object itself
+func (mover *HumanMover) getSamples(object objects.IHitObject) *humanSamples {
+	key := humanObjectKey{id: object.GetID(), time: object.GetStartTime()}
+
+	if samples, ok := mover.samples[key]; ok {
+		return samples
+	}
+
+	rng := rand.New(rand.NewSource(mover.seed ^ key.id<<32 ^ int64(math.Float64bits(key.time))))
+
+	samples := new(humanSamples)
+
+	// Keep hits well inside the 100 window so objects aren't reordered or missed
+	limit := float64(mover.diff.Hit100) / 2
+
+	samples.hitOffset = mutils.Clamp(mover.model.HitOffset.Sample(rng), -limit, limit)
+
+	dist := mutils.Clamp(mover.model.AimError.Sample(rng), 0, 0.8) * mover.diff.CircleRadius
+
+	samples.aimOffset = vector.NewVec2fRad(rng.Float32()*2*math32.Pi, float32(dist))
+
+	samples.curvature = float32(math.Abs(mover.model.Curvature.Sample(rng)))
+	if rng.Intn(2) == 0 {
+		samples.curvature *= -1
+	}
+
+	samples.overshoot = mover.model.Overshoot.Sample(rng)
+	samples.overshootTime = mutils.Clamp(mover.model.OvershootTime.Sample(rng), 10, 150)
+
+	mover.samples[key] = samples
+
+	return samples
+}
+
+func isHumanHittable(object objects.IHitObject) bool {
+	switch o := object.(type) {
+	case *objects.Circle:
+		return !o.SliderPoint || o.SliderPointStart
+	case *objects.Slider:
+		return true
+	}
+
+	return false
+}
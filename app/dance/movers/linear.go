@@ -42,7 +42,7 @@ func (mover *LinearMover) SetObjects(objs []objects.IHitObject) int {
 	if mover.simple {
 		mover.startTime = max(mover.startTime, mover.endTime-(mover.diff.Preempt-100*mover.diff.Speed))
 	} else {
-		config := settings.CursorDance.MoverSettings.Linear[mover.id%len(settings.CursorDance.MoverSettings.Linear)]
+		config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Linear)
 
 		if config.WaitForPreempt {
 			mover.startTime = max(mover.startTime, mover.endTime-(mover.diff.Preempt-config.ReactionTime*mover.diff.Speed))
@@ -58,7 +58,7 @@ func (mover *LinearMover) Update(time float64) vector.Vector2f {
 }
 
 func (mover *LinearMover) GetObjectsPosition(time float64, object objects.IHitObject) vector.Vector2f {
-	config := settings.CursorDance.MoverSettings.Linear[mover.id%len(settings.CursorDance.MoverSettings.Linear)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Linear)
 
 	if !config.ChoppyLongObjects || mover.simple || object.GetType() == objects.CIRCLE {
 		return mover.basicMover.GetObjectsPosition(time, object)
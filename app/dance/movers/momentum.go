@@ -58,7 +58,7 @@ func anorm2(a float32) float32 {
 }
 
 func (mover *MomentumMover) SetObjects(objs []objects.IHitObject) int {
-	ms := settings.CursorDance.MoverSettings.Momentum[mover.id%len(settings.CursorDance.MoverSettings.Momentum)]
+	ms := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Momentum)
 
 	i := 0
 
@@ -22,15 +22,45 @@ type MultiPointMover interface {
 	GetEndTime() float64
 }
 
+// InputStyle is implemented by movers that also decide how keys are pressed
+type InputStyle interface {
+	// GetReleaseDelay returns how long the key is held after object's end
+	GetReleaseDelay() float64
+
+	// GetSingleTapThreshold returns the time between objects below which keys are alternated
+	GetSingleTapThreshold() float64
+}
+
+// Configurable is implemented by movers whose settings can be given directly instead of being picked from CursorDance.MoverSettings by id
+type Configurable interface {
+	SetConfig(config any)
+}
+
 type basicMover struct {
 	startTime float64
 	endTime   float64
 
 	id int
 
+	// config overrides mover's settings, it has to be a pointer to the type of mover's settings
+	config any
+
 	diff *difficulty.Difficulty
 }
 
+func (mover *basicMover) SetConfig(config any) {
+	mover.config = config
+}
+
+// getConfig returns mover's settings set with SetConfig, or settings from the list selected by mover's id
+func getConfig[T any](mover *basicMover, list []*T) *T {
+	if config, ok := mover.config.(*T); ok {
+		return config
+	}
+
+	return list[mover.id%len(list)]
+}
+
 func (mover *basicMover) Reset(diff *difficulty.Difficulty, id int) {
 	mover.diff = diff
 	mover.id = id
@@ -92,6 +122,10 @@ func GetMoverCtorByName(name string) (moverCtor func() MultiPointMover, finalNam
 		moverCtor = NewMomentumMover
 	case "pippi":
 		moverCtor = NewPippiMover
+	case "script":
+		moverCtor = NewScriptMover
+	case "human":
+		moverCtor = NewHumanMover
 	default:
 		moverCtor = NewAngleOffsetMover
 		finalName = "flower"
@@ -84,7 +84,7 @@ func (mover *PippiMover) GetObjectsPosition(time float64, object objects.IHitObj
 }
 
 func (mover *PippiMover) modifyPos(time float64, spinner bool, pos vector.Vector2f) vector.Vector2f {
-	config := settings.CursorDance.MoverSettings.Pippi[mover.id%len(settings.CursorDance.MoverSettings.Pippi)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Pippi)
 
 	rad := math.Mod(time/1000*config.RotationSpeed, 1) * 2 * math.Pi
 
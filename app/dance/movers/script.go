@@ -0,0 +1,372 @@
+package movers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/math/vector"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Limits of executed Lua instructions, scripts exceeding them (e.g. stuck in an infinite loop) fail instead of freezing danser.
+// Instructions are counted instead of measuring time so that results don't depend on the machine or rendering load.
+const (
+	scriptLoadSteps = 50_000_000
+	scriptCallSteps = 1_000_000
+)
+
+var errScriptStepLimit = errors.New("instruction limit exceeded")
+
+var closedDone = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// stepLimitContext counts executed instructions, gopher-lua checks Done before executing each of them
+type stepLimitContext struct {
+	context.Context
+	steps int
+	limit int
+}
+
+func (ctx *stepLimitContext) Done() <-chan struct{} {
+	ctx.steps++
+
+	if ctx.steps > ctx.limit {
+		return closedDone
+	}
+
+	return nil
+}
+
+func (ctx *stepLimitContext) Err() error {
+	if ctx.steps > ctx.limit {
+		return errScriptStepLimit
+	}
+
+	return nil
+}
+
+// Compiled scripts are shared between cursors using the same file
+var scriptCache = make(map[string]*lua.FunctionProto)
+var scriptCacheMutex sync.Mutex
+
+// ScriptMover moves the cursor by calling set_objects and update functions of a Lua script.
+// If the script fails, the cursor moves in a straight line until the next Reset.
+type ScriptMover struct {
+	*basicMover
+
+	state *lua.LState
+
+	setObjects lua.LValue
+	update     lua.LValue
+
+	// Objects between which the cursor currently moves, used to set up the fallback mover mid-movement
+	from, to objects.IHitObject
+
+	fallback MultiPointMover
+	failed   bool
+}
+
+func NewScriptMover() MultiPointMover {
+	return &ScriptMover{
+		basicMover: &basicMover{},
+		fallback:   NewLinearMoverSimple(),
+	}
+}
+
+func (mover *ScriptMover) Reset(diff *difficulty.Difficulty, id int) {
+	mover.basicMover.Reset(diff, id)
+	mover.fallback.Reset(diff, id)
+
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Script)
+
+	mover.Close()
+
+	mover.failed = false
+	mover.state = newScriptState()
+
+	if err := mover.load(config.Path); err != nil {
+		mover.fail(err)
+	}
+}
+
+// Close releases the Lua state, has to be called when the mover is no longer used
+func (mover *ScriptMover) Close() {
+	if mover.state != nil {
+		mover.state.Close()
+		mover.state = nil
+	}
+}
+
+func (mover *ScriptMover) load(path string) error {
+	proto, err := compileScript(path)
+	if err != nil {
+		return err
+	}
+
+	L := mover.state
+
+	L.SetGlobal("cursor", lua.LNumber(mover.id))
+	L.SetGlobal("settings_index", lua.LNumber(mover.id%len(settings.CursorDance.MoverSettings.Script)))
+	L.SetGlobal("difficulty", mover.difficultyTable())
+
+	if err = mover.call(scriptLoadSteps, lua.P{Fn: L.NewFunctionFromProto(proto), NRet: 0, Protect: true}); err != nil {
+		return err
+	}
+
+	mover.setObjects = L.GetGlobal("set_objects")
+	mover.update = L.GetGlobal("update")
+
+	if mover.update.Type() != lua.LTFunction {
+		return fmt.Errorf("%s: update function is not defined", path)
+	}
+
+	return nil
+}
+
+func (mover *ScriptMover) fail(err error) {
+	log.Println("ScriptMover: Script failed, falling back to linear movement:", err)
+
+	mover.failed = true
+}
+
+func (mover *ScriptMover) SetObjects(objs []objects.IHitObject) int {
+	if !mover.failed {
+		n, err := mover.callSetObjects(objs)
+		if err == nil {
+			mover.from, mover.to = objs[0], objs[n-1]
+			return n
+		}
+
+		mover.fail(err)
+	}
+
+	n := mover.fallback.SetObjects(objs)
+
+	mover.startTime = mover.fallback.GetStartTime()
+	mover.endTime = mover.fallback.GetEndTime()
+
+	return n
+}
+
+// callSetObjects passes objects to the script. Script returns the number of used objects (2 if nil) and optionally
+// start and end time of the movement (by default from the end of the first object to the start of the last used one).
+func (mover *ScriptMover) callSetObjects(objs []objects.IHitObject) (int, error) {
+	n := 2
+
+	start, end := objs[0].GetEndTime(), objs[1].GetStartTime()
+
+	if mover.setObjects.Type() != lua.LTFunction {
+		mover.startTime, mover.endTime = start, end
+		return n, nil
+	}
+
+	L := mover.state
+
+	table := L.CreateTable(len(objs), 0)
+
+	for _, o := range objs {
+		table.Append(mover.objectTable(o))
+	}
+
+	if err := mover.call(scriptCallSteps, lua.P{Fn: mover.setObjects, NRet: 3, Protect: true}, table); err != nil {
+		return 0, err
+	}
+
+	retN, retStart, retEnd := L.Get(-3), L.Get(-2), L.Get(-1)
+	L.Pop(3)
+
+	if num, ok := retN.(lua.LNumber); ok {
+		n = int(num)
+
+		if n < 2 || n > len(objs) {
+			return 0, fmt.Errorf("set_objects returned %d, expected a number between 2 and %d", n, len(objs))
+		}
+
+		end = objs[n-1].GetStartTime()
+	}
+
+	if num, ok := retStart.(lua.LNumber); ok {
+		start = float64(num)
+	}
+
+	if num, ok := retEnd.(lua.LNumber); ok {
+		end = float64(num)
+	}
+
+	mover.startTime, mover.endTime = start, end
+
+	return n, nil
+}
+
+func (mover *ScriptMover) Update(time float64) vector.Vector2f {
+	if !mover.failed {
+		L := mover.state
+
+		err := mover.call(scriptCallSteps, lua.P{Fn: mover.update, NRet: 2, Protect: true}, lua.LNumber(time))
+		if err == nil {
+			x, y := L.Get(-2), L.Get(-1)
+			L.Pop(2)
+
+			xN, xOk := x.(lua.LNumber)
+			yN, yOk := y.(lua.LNumber)
+
+			if xOk && yOk {
+				return vector.NewVec2f(float32(xN), float32(yN))
+			}
+
+			err = fmt.Errorf("update returned (%s, %s), expected two numbers", x.Type(), y.Type())
+		}
+
+		mover.fail(err)
+
+		mover.fallback.SetObjects([]objects.IHitObject{mover.from, mover.to})
+	}
+
+	return mover.fallback.Update(time)
+}
+
+// call calls a script function that has to finish within given number of instructions
+func (mover *ScriptMover) call(steps int, p lua.P, args ...lua.LValue) error {
+	mover.state.SetContext(&stepLimitContext{Context: context.Background(), limit: steps})
+	defer mover.state.RemoveContext()
+
+	return mover.state.CallByParam(p, args...)
+}
+
+func (mover *ScriptMover) objectTable(o objects.IHitObject) *lua.LTable {
+	L := mover.state
+
+	startPos := mover.GetObjectsStartPosition(o)
+	endPos := mover.GetObjectsEndPosition(o)
+
+	t := L.CreateTable(0, 16)
+
+	t.RawSetString("start_time", lua.LNumber(mover.GetObjectsStartTime(o)))
+	t.RawSetString("end_time", lua.LNumber(mover.GetObjectsEndTime(o)))
+	t.RawSetString("start_x", lua.LNumber(startPos.X))
+	t.RawSetString("start_y", lua.LNumber(startPos.Y))
+	t.RawSetString("end_x", lua.LNumber(endPos.X))
+	t.RawSetString("end_y", lua.LNumber(endPos.Y))
+	t.RawSetString("new_combo", lua.LBool(o.IsNewCombo()))
+
+	switch {
+	case o.GetType()&objects.SPINNER > 0:
+		t.RawSetString("type", lua.LString("spinner"))
+	case o.GetType()&objects.SLIDER > 0:
+		t.RawSetString("type", lua.LString("slider"))
+	default:
+		t.RawSetString("type", lua.LString("circle"))
+	}
+
+	if c, ok := o.(*objects.Circle); ok {
+		t.RawSetString("double_click", lua.LBool(c.DoubleClick))
+	}
+
+	if s, ok := o.(objects.ILongObject); ok {
+		t.RawSetString("start_angle", lua.LNumber(s.GetStartAngleMod(mover.diff)))
+		t.RawSetString("end_angle", lua.LNumber(s.GetEndAngleMod(mover.diff)))
+	}
+
+	t.RawSetString("position_at", L.NewFunction(func(L *lua.LState) int {
+		pos := mover.GetObjectsPosition(float64(L.CheckNumber(1)), o)
+
+		L.Push(lua.LNumber(pos.X))
+		L.Push(lua.LNumber(pos.Y))
+
+		return 2
+	}))
+
+	return t
+}
+
+func (mover *ScriptMover) difficultyTable() *lua.LTable {
+	t := mover.state.CreateTable(0, 12)
+
+	t.RawSetString("ar", lua.LNumber(mover.diff.GetAR()))
+	t.RawSetString("od", lua.LNumber(mover.diff.GetOD()))
+	t.RawSetString("cs", lua.LNumber(mover.diff.GetCS()))
+	t.RawSetString("hp", lua.LNumber(mover.diff.GetHP()))
+	t.RawSetString("preempt", lua.LNumber(mover.diff.Preempt))
+	t.RawSetString("fade_in", lua.LNumber(mover.diff.TimeFadeIn))
+	t.RawSetString("circle_radius", lua.LNumber(mover.diff.CircleRadius))
+	t.RawSetString("hit300", lua.LNumber(mover.diff.Hit300))
+	t.RawSetString("hit100", lua.LNumber(mover.diff.Hit100))
+	t.RawSetString("hit50", lua.LNumber(mover.diff.Hit50))
+	t.RawSetString("speed", lua.LNumber(mover.diff.Speed))
+	t.RawSetString("mods", lua.LString(mover.diff.GetModString()))
+
+	return t
+}
+
+// newScriptState creates a Lua state without access to files, OS or other scripts
+func newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	libs := []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	}
+
+	for _, lib := range libs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	return L
+}
+
+func compileScript(path string) (*lua.FunctionProto, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(env.DataDir(), path)
+	}
+
+	scriptCacheMutex.Lock()
+	defer scriptCacheMutex.Unlock()
+
+	if proto, ok := scriptCache[path]; ok {
+		return proto, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	chunk, err := parse.Parse(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := lua.Compile(chunk, path)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptCache[path] = proto
+
+	return proto, nil
+}
@@ -29,7 +29,7 @@ func NewSplineMover() MultiPointMover {
 }
 
 func (mover *SplineMover) SetObjects(objs []objects.IHitObject) int {
-	config := settings.CursorDance.MoverSettings.Spline[mover.id%len(settings.CursorDance.MoverSettings.Spline)]
+	config := getConfig(mover.basicMover, settings.CursorDance.MoverSettings.Spline)
 
 	mover.objs = mover.objs[:0]
 	points := make([]vector.Vector2f, 0)
@@ -40,6 +40,9 @@ type PlayerController struct {
 
 	quickRestart     bool
 	quickRestartTime float64
+
+	recorder *ReplayRecorder
+	saved    bool
 }
 
 func NewPlayerController() Controller {
@@ -57,6 +60,7 @@ func (controller *PlayerController) InitCursors() {
 	controller.cursors[0].ScoreTime = time.Now()
 	controller.window = glfw.GetCurrentContext()
 	controller.ruleset = osu.NewOsuRuleset(controller.bMap, controller.cursors, []*difficulty.Difficulty{controller.bMap.Diff.Clone()})
+	controller.recorder = NewReplayRecorder(controller.cursors[0])
 
 	if !controller.bMap.Diff.CheckModActive(difficulty.Relax) {
 		input2.RegisterListener(controller.KeyEvent)
@@ -165,16 +169,45 @@ func (controller *PlayerController) Update(time float64, delta float64) {
 		controller.cursors[0].IsReplayFrame = false
 	}
 
+	controller.recorder.Update(int64(time))
+
 	controller.ruleset.UpdateClickFor(controller.cursors[0], int64(time))
 	controller.ruleset.UpdateNormalFor(controller.cursors[0], int64(time), false)
 	controller.ruleset.UpdatePostFor(controller.cursors[0], int64(time), false)
 	controller.ruleset.Update(int64(time))
 
+	controller.recorder.UpdateLife(int64(time), controller.ruleset.GetHP(controller.cursors[0]))
+
+	if controller.ruleset.IsEnded() && !controller.saved {
+		controller.saveReplay()
+	}
+
 	controller.lastTime = time
 
 	controller.cursors[0].Update(delta)
 }
 
+func (controller *PlayerController) saveReplay() {
+	controller.saved = true
+
+	if !settings.Gameplay.SavePlayReplays || controller.ruleset.IsFailed(controller.cursors[0]) {
+		return
+	}
+
+	diff := controller.ruleset.GetPlayerDifficulty(controller.cursors[0])
+	replay := controller.recorder.Build(controller.bMap, diff.Mods, controller.ruleset.GetScore(controller.cursors[0]))
+
+	path, err := SaveReplay(controller.bMap, replay)
+	if err != nil {
+		log.Println("Failed to save the replay:", err)
+		return
+	}
+
+	controller.cursors[0].ReplayPath = path
+
+	log.Println("Replay saved to:", path)
+}
+
 func (controller *PlayerController) GetRuleset() *osu.OsuRuleSet {
 	return controller.ruleset
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/wieku/danser-go/app/dance/movers"
 	"github.com/wieku/danser-go/app/dance/schedulers"
 	"github.com/wieku/danser-go/app/dance/spinners"
+	"github.com/wieku/danser-go/app/database"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
@@ -20,6 +21,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -46,6 +48,8 @@ type subControl struct {
 	replayIndex     int
 	replayTime      float64
 	frames          []*rplpa.ReplayData
+	lifeBar         []vector.Vector2d
+	replayPath      string
 	newHandling     bool
 	lastTime        int64
 	oldSpinners     bool
@@ -68,12 +72,14 @@ type ReplayController struct {
 	controllers []*subControl
 	ruleset     *osu.OsuRuleSet
 	lastTime    float64
+
+	replayPaths map[*rplpa.Replay]string
 }
 
 func NewReplayController() Controller {
 	_ = os.MkdirAll(filepath.Join(env.DataDir(), replaysMaster), 0755)
 
-	return &ReplayController{lastTime: -200}
+	return &ReplayController{lastTime: -200, replayPaths: make(map[*rplpa.Replay]string)}
 }
 
 func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
@@ -98,6 +104,7 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 			log.Println("Excluding for missing input data:", replayD.Username)
 		} else {
 			candidates = append(candidates, replayD)
+			controller.replayPaths[replayD] = settings.REPLAY
 
 			localReplay = true
 		}
@@ -150,6 +157,12 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 
 		loadFrames(control, replay.ReplayData)
 
+		control.replayPath = controller.replayPaths[replay]
+
+		for _, l := range replay.LifebarGraph {
+			control.lifeBar = append(control.lifeBar, vector.NewVec2d(float64(l.Time), float64(l.HP)))
+		}
+
 		mxCombo := replay.MaxCombo
 
 		control.newHandling = replay.OsuVersion >= 20190506 // This was when slider scoring was changed, so *I think* replay handling as well: https://osu.ppy.sh/home/changelog/cuttingedge/20190506
@@ -219,8 +232,9 @@ func organizeReplays() {
 
 func (controller *ReplayController) getCandidates() (candidates []*rplpa.Replay) {
 	excludedMods := difficulty.ParseMods(settings.Knockout.ExcludeMods)
+	dateFrom, dateTo := getDateRange()
 
-	tryAddReplay := func(path string, modExclude bool) {
+	tryAddReplay := func(path string, filter bool) {
 		log.Println("Loading: ", path)
 
 		data, err := os.ReadFile(path)
@@ -245,17 +259,28 @@ func (controller *ReplayController) getCandidates() (candidates []*rplpa.Replay)
 			return
 		}
 
-		if (replayD.Mods&uint32(excludedMods)) > 0 && modExclude {
+		if (replayD.Mods&uint32(excludedMods)) > 0 && filter {
 			log.Println("Excluding for mods:", replayD.Username)
 			return
 		}
 
+		if !inDateRange(replayD.Timestamp, dateFrom, dateTo) && filter {
+			log.Println("Excluding for date:", replayD.Username, replayD.Timestamp.Format(time.DateOnly))
+			return
+		}
+
+		if replayD.ReplayMD5 != "" && slices.ContainsFunc(candidates, func(r *rplpa.Replay) bool { return strings.EqualFold(r.ReplayMD5, replayD.ReplayMD5) }) {
+			log.Println("Excluding duplicate replay:", replayD.Username)
+			return
+		}
+
 		if replayD.ReplayData == nil || len(replayD.ReplayData) == 0 {
 			log.Println("Excluding for missing input data:", replayD.Username)
 			return
 		}
 
 		candidates = append(candidates, replayD)
+		controller.replayPaths[replayD] = path
 	}
 
 	if settings.KNOCKOUTREPLAYS != nil && len(settings.KNOCKOUTREPLAYS) > 0 {
@@ -270,11 +295,77 @@ func (controller *ReplayController) getCandidates() (candidates []*rplpa.Replay)
 		for _, replayPath := range replayPaths {
 			tryAddReplay(replayPath, true)
 		}
+
+		if settings.Knockout.OsuScores {
+			for _, replayPath := range getOsuReplays(controller.bMap.MD5, excludedMods, dateFrom, dateTo) {
+				tryAddReplay(replayPath, true)
+			}
+		}
 	}
 
 	return
 }
 
+// getOsuReplays finds replays of local plays stored in osu!stable's scores.db. Scores are filtered before replays are loaded.
+func getOsuReplays(md5 string, excludedMods difficulty.Modifier, dateFrom, dateTo time.Time) (paths []string) {
+	log.Println("Reading osu!'s scores.db...")
+
+	scores, err := database.ReadOsuScores(database.OsuScoresPath())
+	if err != nil {
+		log.Println("Failed to read scores.db:", err)
+		return
+	}
+
+	mapScores := scores[strings.ToLower(md5)]
+
+	log.Println(fmt.Sprintf("Found %d plays in scores.db", len(mapScores)))
+
+	for _, score := range mapScores {
+		if score.Mode != 0 || score.Mods&excludedMods > 0 || !inDateRange(score.Timestamp, dateFrom, dateTo) {
+			continue
+		}
+
+		path := score.FindReplay()
+		if path == "" {
+			log.Println(fmt.Sprintf("Replay of %s's play from %s is missing, skipping", score.Player, score.Timestamp.Format(time.DateTime)))
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return
+}
+
+// getDateRange parses Knockout.DateFrom and Knockout.DateTo, zero time means no limit
+func getDateRange() (from, to time.Time) {
+	parse := func(name, value string) time.Time {
+		if strings.TrimSpace(value) == "" {
+			return time.Time{}
+		}
+
+		date, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(value), time.Local)
+		if err != nil {
+			log.Println(fmt.Sprintf("Invalid %s date \"%s\", ignoring: %s", name, value, err))
+			return time.Time{}
+		}
+
+		return date
+	}
+
+	from = parse("Knockout.DateFrom", settings.Knockout.DateFrom)
+
+	if to = parse("Knockout.DateTo", settings.Knockout.DateTo); !to.IsZero() {
+		to = to.AddDate(0, 0, 1) // Include the whole last day
+	}
+
+	return
+}
+
+func inDateRange(date, from, to time.Time) bool {
+	return (from.IsZero() || !date.Before(from)) && (to.IsZero() || date.Before(to))
+}
+
 func loadFrames(subController *subControl, frames []*rplpa.ReplayData) {
 	// Remove mania seed frame if its present
 	for i, frame := range frames {
@@ -350,6 +441,8 @@ func (controller *ReplayController) InitCursors() {
 			cursor.ScoreTime = controller.replays[i].ScoreTime
 			cursor.OldSpinnerScoring = controller.controllers[i].oldSpinners
 			cursor.ModifiedMods = controller.controllers[i].modifiedMods
+			cursor.LifeBar = controller.controllers[i].lifeBar
+			cursor.ReplayPath = controller.controllers[i].replayPath
 			cursor.IsReplay = true
 
 			cursor.SetPos(vector.NewVec2d(c.frames[0].MouseX, c.frames[0].MouseY).Copy32())
@@ -421,7 +514,9 @@ func (controller *ReplayController) Update(time float64, delta float64) {
 }
 
 func (controller *ReplayController) updateMain(nTime float64) {
-	controller.bMap.Update(nTime)
+	if !settings.HEADLESS {
+		controller.bMap.Update(nTime)
+	}
 
 	for i, c := range controller.controllers {
 		if c.danceController != nil {
@@ -0,0 +1,155 @@
+package dance
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/rplpa"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	recordInterval  = 1000.0 / 60
+	lifeBarInterval = 2000
+
+	legacyVersion = 20250101
+	lazerVersion  = 30000001
+)
+
+// ReplayRecorder captures cursor's position and key presses in osu!stable replay format
+type ReplayRecorder struct {
+	cursor *graphics.Cursor
+
+	frames  []*rplpa.ReplayData
+	lifeBar []rplpa.LifeBarGraph
+
+	lastFrame int64
+	lastLife  int64
+	lastKeys  rplpa.KeyPressed
+	started   bool
+}
+
+func NewReplayRecorder(cursor *graphics.Cursor) *ReplayRecorder {
+	return &ReplayRecorder{
+		cursor: cursor,
+		frames: []*rplpa.ReplayData{ // osu!stable always starts replays with those two frames
+			{Time: 0, MouseX: 256, MouseY: -500, KeyPressed: &rplpa.KeyPressed{}},
+			{Time: -1, MouseX: 256, MouseY: -500, KeyPressed: &rplpa.KeyPressed{}},
+		},
+		lastFrame: -1,
+		lastLife:  math.MinInt64,
+	}
+}
+
+// Update records a new frame if enough time has passed or key state has changed, returns true if frame was recorded
+func (recorder *ReplayRecorder) Update(time int64) bool {
+	delta := time - recorder.lastFrame
+	if delta <= 0 && recorder.started { // first frame can go back before the beginning of the song
+		return false
+	}
+
+	keys := rplpa.KeyPressed{
+		LeftClick:  recorder.cursor.LeftButton || recorder.cursor.LeftKey || recorder.cursor.LeftMouse,
+		RightClick: recorder.cursor.RightButton || recorder.cursor.RightKey || recorder.cursor.RightMouse,
+		Key1:       recorder.cursor.LeftKey,
+		Key2:       recorder.cursor.RightKey,
+		Smoke:      recorder.cursor.SmokeKey,
+	}
+
+	if recorder.started && float64(delta) < recordInterval && keys == recorder.lastKeys {
+		return false
+	}
+
+	recorder.frames = append(recorder.frames, &rplpa.ReplayData{
+		Time:       float64(delta),
+		MouseX:     float64(recorder.cursor.RawPosition.X),
+		MouseY:     float64(recorder.cursor.RawPosition.Y),
+		KeyPressed: &keys,
+	})
+
+	recorder.lastFrame = time
+	recorder.lastKeys = keys
+	recorder.started = true
+
+	return true
+}
+
+// UpdateLife samples the life bar, hp has to be in 0-1 range
+func (recorder *ReplayRecorder) UpdateLife(time int64, hp float64) {
+	if time-recorder.lastLife < lifeBarInterval {
+		return
+	}
+
+	recorder.lifeBar = append(recorder.lifeBar, rplpa.LifeBarGraph{
+		Time: int32(time),
+		HP:   float32(hp),
+	})
+
+	recorder.lastLife = time
+}
+
+// Build creates a replay for given beatmap. Score can be empty if the input wasn't judged
+func (recorder *ReplayRecorder) Build(beatMap *beatmap.BeatMap, mods difficulty.Modifier, score osu.Score) *rplpa.Replay {
+	replay := rplpa.NewReplay()
+
+	replay.PlayMode = 0
+	replay.OsuVersion = legacyVersion
+	if mods.Active(difficulty.Lazer) {
+		replay.OsuVersion = lazerVersion
+	}
+
+	replay.BeatmapMD5 = beatMap.MD5
+	replay.Username = recorder.cursor.Name
+
+	replay.Count300 = uint16(score.Count300)
+	replay.Count100 = uint16(score.Count100)
+	replay.Count50 = uint16(score.Count50)
+	replay.CountGeki = uint16(score.CountGeki)
+	replay.CountKatu = uint16(score.CountKatu)
+	replay.CountMiss = uint16(score.CountMiss)
+	replay.Score = int32(min(score.Score, math.MaxInt32))
+	replay.MaxCombo = uint16(score.Combo)
+	replay.Fullcombo = score.PerfectCombo
+	replay.Mods = uint32(mods.Legacy())
+	replay.LifebarGraph = recorder.lifeBar
+	replay.Timestamp = time.Now().UTC()
+	replay.ReplayData = recorder.frames
+
+	hash := md5.Sum([]byte(fmt.Sprintf("%d%s%s%d%d%d%d%d%d%d%d%d", replay.OsuVersion, replay.BeatmapMD5, replay.Username, replay.Count300, replay.Count100, replay.Count50, replay.CountGeki, replay.CountKatu, replay.CountMiss, replay.Score, replay.MaxCombo, replay.Timestamp.UnixNano())))
+	replay.ReplayMD5 = hex.EncodeToString(hash[:])
+
+	return replay
+}
+
+// SaveReplay writes the replay to danser's replay directory, next to other replays of that beatmap
+func SaveReplay(beatMap *beatmap.BeatMap, replay *rplpa.Replay) (string, error) {
+	data, err := rplpa.WriteReplay(replay)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(env.DataDir(), replaysMaster, beatMap.MD5)
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s - %s - %s [%s] (%s) Osu.osr", replay.Username, beatMap.Artist, beatMap.Name, beatMap.Difficulty, replay.Timestamp.Format("2006-01-02_15-04-05"))
+
+	path := filepath.Join(dir, files.FixName(name))
+
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
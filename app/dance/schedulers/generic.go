@@ -12,19 +12,53 @@ import (
 	"math/rand"
 )
 
+// MoverSection makes the scheduler use Mover for movements starting between StartTime and EndTime.
+// ID is passed to Mover's Reset and selects mover's settings, unless Settings are given.
+type MoverSection struct {
+	StartTime float64
+	EndTime   float64
+	Mover     movers.MultiPointMover
+	ID        int
+	Settings  any
+}
+
 type GenericScheduler struct {
-	cursor   *graphics.Cursor
-	queue    []objects.IHitObject
-	mover    movers.MultiPointMover
-	lastTime float64
-	input    *input.NaturalInputProcessor
-	diff     *difficulty.Difficulty
-	index    int
-	id       int
+	cursor       *graphics.Cursor
+	queue        []objects.IHitObject
+	mover        movers.MultiPointMover
+	defaultMover movers.MultiPointMover
+	sections     []MoverSection
+	lastTime     float64
+	input        *input.NaturalInputProcessor
+	diff         *difficulty.Difficulty
+	index        int
+	id           int
 }
 
 func NewGenericScheduler(mover func() movers.MultiPointMover, index, id int) Scheduler {
-	return &GenericScheduler{mover: mover(), index: index, id: id}
+	m := mover()
+
+	return &GenericScheduler{mover: m, defaultMover: m, index: index, id: id}
+}
+
+// SetMoverSections sets movers used in parts of the beatmap, later sections take precedence. Has to be called before Init.
+func (scheduler *GenericScheduler) SetMoverSections(sections []MoverSection) {
+	scheduler.sections = sections
+}
+
+// Close releases resources held by scheduler's movers, like Lua states of script movers
+func (scheduler *GenericScheduler) Close() {
+	closeMover(scheduler.defaultMover)
+
+	for _, s := range scheduler.sections {
+		closeMover(s.Mover)
+	}
+}
+
+func closeMover(mover movers.MultiPointMover) {
+	if c, ok := mover.(interface{ Close() }); ok {
+		c.Close()
+	}
 }
 
 func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficulty.Difficulty, cursor *graphics.Cursor, spinnerMoverCtor func() spinners.SpinnerMover, initKeys bool) {
@@ -32,8 +66,17 @@ func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficu
 	scheduler.cursor = cursor
 	scheduler.queue = objs
 
+	scheduler.mover = scheduler.defaultMover
 	scheduler.mover.Reset(diff, scheduler.id)
 
+	for _, s := range scheduler.sections {
+		if c, ok := s.Mover.(movers.Configurable); ok && s.Settings != nil {
+			c.SetConfig(s.Settings)
+		}
+
+		s.Mover.Reset(diff, s.ID)
+	}
+
 	config := settings.CursorDance.Movers[scheduler.index%len(settings.CursorDance.Movers)]
 
 	// Slider dance / random slider dance resolving
@@ -94,7 +137,7 @@ func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficu
 	}
 
 	if initKeys {
-		scheduler.input = input.NewNaturalInputProcessor(scheduler.queue, cursor, scheduler.mover, diff.GetSpeed())
+		scheduler.input = input.NewNaturalInputProcessor(scheduler.queue, cursor, func() movers.MultiPointMover { return scheduler.mover }, diff.GetSpeed())
 	}
 
 	scheduler.queue = append([]objects.IHitObject{objects.DummyCircle(vector.NewVec2f(100, 100), -500)}, scheduler.queue...)
@@ -102,7 +145,7 @@ func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficu
 	scheduler.cursor.SetPos(vector.NewVec2f(100, 100))
 	scheduler.cursor.Update(0)
 
-	toRemove := scheduler.mover.SetObjects(scheduler.queue) - 1
+	toRemove := scheduler.setObjects(scheduler.queue) - 1
 	scheduler.queue = scheduler.queue[toRemove:]
 }
 
@@ -146,7 +189,7 @@ func (scheduler *GenericScheduler) Update(time float64) {
 				toRemove := 1
 
 				if upperLimit-i > 1 {
-					toRemove = scheduler.mover.SetObjects(scheduler.queue[i:upperLimit]) - 1
+					toRemove = scheduler.setObjects(scheduler.queue[i:upperLimit]) - 1
 				}
 
 				scheduler.queue = append(scheduler.queue[:i], scheduler.queue[i+toRemove:]...)
@@ -165,3 +208,30 @@ func (scheduler *GenericScheduler) Update(time float64) {
 
 	scheduler.lastTime = time
 }
+
+// setObjects picks the mover for the movement starting at the first object and passes objects to it
+func (scheduler *GenericScheduler) setObjects(objs []objects.IHitObject) int {
+	mover := scheduler.moverAt(objs[0].GetEndTime())
+
+	// Movement can't cross a section boundary, next mover starts from the object at the boundary
+	for j := 1; j < len(objs)-1; j++ {
+		if scheduler.moverAt(objs[j].GetEndTime()) != mover {
+			objs = objs[:j+1]
+			break
+		}
+	}
+
+	scheduler.mover = mover
+
+	return mover.SetObjects(objs)
+}
+
+func (scheduler *GenericScheduler) moverAt(time float64) movers.MultiPointMover {
+	for i := len(scheduler.sections) - 1; i >= 0; i-- {
+		if s := scheduler.sections[i]; time >= s.StartTime && time < s.EndTime {
+			return s.Mover
+		}
+	}
+
+	return scheduler.defaultMover
+}
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/framework/bass"
+	"github.com/wieku/danser-go/framework/files"
+	"log"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Directory scans are reused by difficulties of the same set, older ones are dropped to keep memory in check
+const assetCheckerCacheSize = 64
+
+const importErrorsTableStmt = `
+		CREATE TABLE IF NOT EXISTS import_errors (dir TEXT, file TEXT, error TEXT, time INTEGER);
+		CREATE INDEX IF NOT EXISTS import_errors_idx ON import_errors (dir, file);`
+
+// assetChecker checks whether files referenced by beatmaps exist and audio can be decoded. Safe for concurrent use.
+type assetChecker struct {
+	mutex sync.Mutex
+
+	dirs    map[string]*files.FileMap
+	decoded map[string]bool
+}
+
+func newAssetChecker() *assetChecker {
+	return &assetChecker{
+		dirs:    make(map[string]*files.FileMap),
+		decoded: make(map[string]bool),
+	}
+}
+
+func (c *assetChecker) check(bMap *beatmap.BeatMap) (status beatmap.AssetStatus) {
+	fileMap := c.getFileMap(bMap.Dir)
+
+	exists := func(name string) (string, bool) {
+		if fileMap == nil || name == "" {
+			return "", false
+		}
+
+		path, err := fileMap.GetFile(name)
+
+		return path, err == nil
+	}
+
+	if audioPath, ok := exists(bMap.Audio); !ok {
+		status |= beatmap.AudioMissing
+	} else if !bass.Initialized() {
+		status |= beatmap.AudioUnverified
+	} else if !c.canDecode(audioPath) {
+		status |= beatmap.AudioUndecodable
+	}
+
+	if _, ok := exists(bMap.Bg); bMap.Bg != "" && !ok {
+		status |= beatmap.BackgroundMissing
+	}
+
+	if _, ok := exists(bMap.Video); bMap.Video != "" && !ok {
+		status |= beatmap.VideoMissing
+	}
+
+	return
+}
+
+func (c *assetChecker) getFileMap(dir string) *files.FileMap {
+	c.mutex.Lock()
+	fileMap, ok := c.dirs[dir]
+	c.mutex.Unlock()
+
+	if ok {
+		return fileMap
+	}
+
+	fileMap, _ = files.NewFileMap(filepath.Join(songsDir, dir))
+
+	c.mutex.Lock()
+
+	if len(c.dirs) >= assetCheckerCacheSize {
+		clear(c.dirs)
+	}
+
+	c.dirs[dir] = fileMap
+
+	c.mutex.Unlock()
+
+	return fileMap
+}
+
+func (c *assetChecker) canDecode(path string) bool {
+	c.mutex.Lock()
+	decodable, ok := c.decoded[path]
+	c.mutex.Unlock()
+
+	if ok {
+		return decodable
+	}
+
+	decodable = bass.CanDecode(path)
+
+	c.mutex.Lock()
+	c.decoded[path] = decodable
+	c.mutex.Unlock()
+
+	return decodable
+}
+
+// ImportError is a .osu file that couldn't be imported
+type ImportError struct {
+	Dir   string `json:"dir"`
+	File  string `json:"file"`
+	Error string `json:"error"`
+	Time  int64  `json:"time"`
+}
+
+// saveImportErrors replaces errors of attempted files with new ones, errors of files that no longer exist are removed
+func saveImportErrors(attempted []mapLocation, existing map[mapLocation]bool, importErrors []ImportError) {
+	toDelete := slices.Clone(attempted)
+
+	if existing != nil {
+		for _, e := range GetImportErrors() {
+			if l := (mapLocation{dir: e.Dir, file: e.File}); !existing[l] {
+				toDelete = append(toDelete, l)
+			}
+		}
+	}
+
+	if len(toDelete) == 0 && len(importErrors) == 0 {
+		return
+	}
+
+	tx, err := dbFile.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	stDelete, err := tx.Prepare("DELETE FROM import_errors WHERE dir = ? AND file = ?")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, l := range toDelete {
+		if _, err1 := stDelete.Exec(l.dir, l.file); err1 != nil {
+			log.Println(err1)
+		}
+	}
+
+	stInsert, err := tx.Prepare("INSERT INTO import_errors VALUES (?, ?, ?, ?)")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, e := range importErrors {
+		if _, err1 := stInsert.Exec(e.Dir, e.File, e.Error, e.Time); err1 != nil {
+			log.Println(err1)
+		}
+	}
+
+	stDelete.Close()
+	stInsert.Close()
+
+	if err = tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+func newImportError(location mapLocation, err any) ImportError {
+	return ImportError{
+		Dir:   location.dir,
+		File:  location.file,
+		Error: fmt.Sprint(err),
+		Time:  time.Now().UnixNano() / 1000000,
+	}
+}
+
+// GetImportErrors returns .osu files that failed to import during the last import
+func GetImportErrors() []ImportError {
+	res, err := dbFile.Query("SELECT dir, file, error, time FROM import_errors ORDER BY dir, file")
+	if err != nil {
+		panic(err)
+	}
+
+	defer res.Close()
+
+	importErrors := make([]ImportError, 0)
+
+	for res.Next() {
+		var e ImportError
+
+		if err = res.Scan(&e.Dir, &e.File, &e.Error, &e.Time); err != nil {
+			log.Println(err)
+			continue
+		}
+
+		importErrors = append(importErrors, e)
+	}
+
+	return importErrors
+}
+
+// RecheckAssets checks assets of given beatmaps again and saves the results
+func RecheckAssets(maps []*beatmap.BeatMap, progressListener func(processed, target int)) {
+	checker := newAssetChecker()
+
+	tx, err := dbFile.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	st, err := tx.Prepare("UPDATE beatmaps SET assets = ? WHERE dir = ? AND file = ?")
+	if err != nil {
+		panic(err)
+	}
+
+	for i, bMap := range maps {
+		if bMap.Video == "" {
+			// Video isn't stored in the database, so header has to be read again
+			beatmap.ParseHeaderExtras(bMap)
+		}
+
+		bMap.Assets = checker.check(bMap)
+
+		if _, err1 := st.Exec(bMap.Assets, bMap.Dir, bMap.File); err1 != nil {
+			log.Println(err1)
+		}
+
+		if progressListener != nil {
+			progressListener(i+1, len(maps))
+		}
+	}
+
+	st.Close()
+
+	if err = tx.Commit(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/wieku/danser-go/framework/env"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// collectionDBVersion is written to the header of collection.db, osu!stable accepts older versions
+const collectionDBVersion = 20150203
+
+// Collection is a named list of beatmap MD5s, the same as in osu!stable's collection.db
+type Collection struct {
+	Name string
+	MD5s []string
+
+	// modified is true for collections created or changed in danser, only those are saved to danser's collection.db
+	modified bool
+}
+
+// NewCollection creates an empty collection that will be saved to danser's collection.db
+func NewCollection(name string) *Collection {
+	return &Collection{
+		Name:     name,
+		modified: true,
+	}
+}
+
+func (c *Collection) Contains(md5 string) bool {
+	return slices.ContainsFunc(c.MD5s, func(s string) bool {
+		return strings.EqualFold(s, md5)
+	})
+}
+
+// Toggle adds the MD5 to collection or removes it if it's already there. Returns true if it was added.
+func (c *Collection) Toggle(md5 string) bool {
+	if i := slices.IndexFunc(c.MD5s, func(s string) bool { return strings.EqualFold(s, md5) }); i > -1 {
+		c.MD5s = slices.Delete(c.MD5s, i, i+1)
+		c.modified = true
+
+		return false
+	}
+
+	c.MD5s = append(c.MD5s, strings.ToLower(md5))
+	c.modified = true
+
+	return true
+}
+
+// OsuCollectionsPath returns the location of osu!stable's collection.db
+func OsuCollectionsPath() string {
+	return filepath.Join(osuDir(), "collection.db")
+}
+
+// DanserCollectionsPath returns the location of collections created or modified in danser
+func DanserCollectionsPath() string {
+	return filepath.Join(env.DataDir(), "collection.db")
+}
+
+// ReadCollections parses collection.db in osu!stable's format
+func ReadCollections(path string) ([]*Collection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	r := &osuDBReader{reader: bufio.NewReader(file)}
+
+	r.version = r.int()
+
+	count := r.int()
+	if count < 0 {
+		return nil, errors.New("invalid collection count")
+	}
+
+	collections := make([]*Collection, 0, count)
+
+	for range count {
+		collection := &Collection{
+			Name: r.string(),
+		}
+
+		mapCount := r.int()
+		if mapCount < 0 {
+			return nil, errors.New("invalid beatmap count")
+		}
+
+		for range mapCount {
+			if md5 := r.string(); md5 != "" {
+				collection.MD5s = append(collection.MD5s, md5)
+			}
+		}
+
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections, r.err
+}
+
+// WriteCollections saves collections in osu!stable's collection.db format.
+// Data is written to a temporary file first so a failed write doesn't corrupt the existing one.
+func WriteCollections(path string, collections []*Collection) error {
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+
+	writeString := func(s string) {
+		w.WriteByte(0x0b)
+		w.Write(binary.AppendUvarint(nil, uint64(len(s))))
+		w.WriteString(s)
+	}
+
+	binary.Write(w, binary.LittleEndian, int32(collectionDBVersion))
+	binary.Write(w, binary.LittleEndian, int32(len(collections)))
+
+	for _, c := range collections {
+		writeString(c.Name)
+
+		binary.Write(w, binary.LittleEndian, int32(len(c.MD5s)))
+
+		for _, md5 := range c.MD5s {
+			writeString(md5)
+		}
+	}
+
+	err = w.Flush()
+
+	if err1 := file.Close(); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// LoadCollections loads osu!stable's collections merged with the ones saved by danser.
+// danser's version of a collection takes precedence over the one with the same name in osu!.
+func LoadCollections() []*Collection {
+	collections, err := ReadCollections(DanserCollectionsPath())
+	if err != nil && !os.IsNotExist(err) {
+		log.Println("DatabaseManager: Failed to read danser's collection.db:", err)
+	}
+
+	for _, c := range collections {
+		c.modified = true
+	}
+
+	osuCollections, err := ReadCollections(OsuCollectionsPath())
+	if err != nil && !os.IsNotExist(err) {
+		log.Println("DatabaseManager: Failed to read osu!'s collection.db:", err)
+	}
+
+	for _, c := range osuCollections {
+		if FindCollection(collections, c.Name) == nil {
+			collections = append(collections, c)
+		}
+	}
+
+	return collections
+}
+
+// SaveCollections saves collections created or modified in danser to danser's collection.db.
+// Untouched osu! collections are skipped so later changes made in osu! aren't shadowed by danser's copy.
+func SaveCollections(collections []*Collection) error {
+	modified := make([]*Collection, 0, len(collections))
+
+	for _, c := range collections {
+		if c.modified {
+			modified = append(modified, c)
+		}
+	}
+
+	return WriteCollections(DanserCollectionsPath(), modified)
+}
+
+// ExportCollections overwrites osu!stable's collection.db, the file from before the first export is kept as collection.db.bak.
+// osu! has to be closed, otherwise it will overwrite the file on exit.
+func ExportCollections(collections []*Collection) error {
+	path := OsuCollectionsPath()
+
+	// Later exports would replace the backup with danser's own file
+	if _, err := os.Stat(path + ".bak"); os.IsNotExist(err) {
+		if _, err = os.Stat(path); err == nil {
+			if err = os.Rename(path, path+".bak"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return WriteCollections(path, collections)
+}
+
+// FindCollection returns the collection with given name, case-insensitive
+func FindCollection(collections []*Collection, name string) *Collection {
+	for _, c := range collections {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+
+	return nil
+}
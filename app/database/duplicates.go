@@ -0,0 +1,311 @@
+package database
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/files"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// copySuffix matches names of re-downloaded sets, e.g. "123 Artist - Title (1)"
+var copySuffix = regexp.MustCompile(`\s*\(\d+\)$`)
+
+// DuplicateDir is a beatmap set directory that shares beatmaps with other directories
+type DuplicateDir struct {
+	Dir          string `json:"dir"`
+	Size         int64  `json:"size"`
+	Beatmaps     int    `json:"beatmaps"`
+	LastModified int64  `json:"lastModified"`
+	Keep         bool   `json:"keep"`
+
+	// Redundant is true if all beatmaps of the directory are also in the kept one, only those directories are quarantined
+	Redundant bool `json:"redundant"`
+
+	md5s []string
+}
+
+// DuplicateGroup is a set of directories containing the same beatmaps (by MD5) or the same beatmap set (by set ID).
+// Exactly one directory in the group is marked to be kept, directories with beatmaps missing from it are only reported.
+type DuplicateGroup struct {
+	Title     string          `json:"title"`
+	SetID     int64           `json:"setId"`
+	SameMD5   bool            `json:"sameMd5"`
+	SameSetID bool            `json:"sameSetId"`
+	Dirs      []*DuplicateDir `json:"dirs"`
+}
+
+// RedundantSize returns the size of redundant directories
+func (g *DuplicateGroup) RedundantSize() (size int64) {
+	for _, d := range g.Dirs {
+		if d.Redundant {
+			size += d.Size
+		}
+	}
+
+	return
+}
+
+// FindDuplicates groups directories that share a beatmap MD5 or a set ID
+func FindDuplicates() []*DuplicateGroup {
+	beatmaps := loadBeatmapsFromDatabase()
+
+	dirs := make(map[string]*DuplicateDir)
+	dirMaps := make(map[string][]*beatmap.BeatMap)
+
+	for _, b := range beatmaps {
+		d, ok := dirs[b.Dir]
+		if !ok {
+			d = &DuplicateDir{Dir: b.Dir}
+			dirs[b.Dir] = d
+		}
+
+		d.Beatmaps++
+		d.LastModified = max(d.LastModified, b.LastModified)
+
+		if b.MD5 != "" {
+			d.md5s = append(d.md5s, strings.ToLower(b.MD5))
+		}
+
+		dirMaps[b.Dir] = append(dirMaps[b.Dir], b)
+	}
+
+	// Directories are connected if they share an MD5 or a set ID, connected components are duplicate groups
+	parent := make(map[string]string, len(dirs))
+
+	var find func(dir string) string
+	find = func(dir string) string {
+		if p, ok := parent[dir]; ok && p != dir {
+			parent[dir] = find(p)
+			return parent[dir]
+		}
+
+		return dir
+	}
+
+	byMD5 := make(map[string]string)
+	bySetID := make(map[int64]string)
+
+	sameMD5 := make(map[[2]string]bool)
+	sameSetID := make(map[[2]string]bool)
+
+	union := func(a, b string, reasons map[[2]string]bool) {
+		if a == b {
+			return
+		}
+
+		reasons[[2]string{min(a, b), max(a, b)}] = true
+
+		if rA, rB := find(a), find(b); rA != rB {
+			parent[rA] = rB
+		}
+	}
+
+	for _, b := range beatmaps {
+		if md5 := strings.ToLower(b.MD5); md5 != "" {
+			if other, ok := byMD5[md5]; ok {
+				union(b.Dir, other, sameMD5)
+			} else {
+				byMD5[md5] = b.Dir
+			}
+		}
+
+		if b.SetID > 0 {
+			if other, ok := bySetID[b.SetID]; ok {
+				union(b.Dir, other, sameSetID)
+			} else {
+				bySetID[b.SetID] = b.Dir
+			}
+		}
+	}
+
+	components := make(map[string][]*DuplicateDir)
+
+	for dir, d := range dirs {
+		root := find(dir)
+		components[root] = append(components[root], d)
+	}
+
+	groups := make([]*DuplicateGroup, 0)
+
+	for _, members := range components {
+		if len(members) < 2 {
+			continue
+		}
+
+		group := &DuplicateGroup{
+			Dirs: members,
+		}
+
+		for i, a := range members {
+			for _, b := range members[i+1:] {
+				key := [2]string{min(a.Dir, b.Dir), max(a.Dir, b.Dir)}
+
+				group.SameMD5 = group.SameMD5 || sameMD5[key]
+				group.SameSetID = group.SameSetID || sameSetID[key]
+			}
+		}
+
+		for _, d := range members {
+			d.Size = dirSize(filepath.Join(songsDir, d.Dir))
+		}
+
+		slices.SortFunc(group.Dirs, compareDuplicates)
+
+		group.Dirs[0].Keep = true
+
+		for _, d := range group.Dirs[1:] {
+			d.Redundant = containsAll(group.Dirs[0].md5s, d.md5s)
+		}
+
+		kept := dirMaps[group.Dirs[0].Dir][0]
+
+		group.Title = fmt.Sprintf("%s - %s", kept.Artist, kept.Name)
+		group.SetID = kept.SetID
+
+		groups = append(groups, group)
+	}
+
+	slices.SortFunc(groups, func(a, b *DuplicateGroup) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+
+	return groups
+}
+
+// compareDuplicates puts the directory that should be kept first: the one with most difficulties,
+// then the newest one unless they're identical, then the one without a copy suffix
+func compareDuplicates(a, b *DuplicateDir) int {
+	if c := cmp.Compare(b.Beatmaps, a.Beatmaps); c != 0 {
+		return c
+	}
+
+	if !sameContents(a, b) {
+		if c := cmp.Compare(b.LastModified, a.LastModified); c != 0 {
+			return c
+		}
+	}
+
+	aCopy, bCopy := copySuffix.MatchString(a.Dir), copySuffix.MatchString(b.Dir)
+
+	if aCopy != bCopy {
+		if aCopy {
+			return 1
+		}
+
+		return -1
+	}
+
+	if c := cmp.Compare(len(a.Dir), len(b.Dir)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Dir, b.Dir)
+}
+
+func sameContents(a, b *DuplicateDir) bool {
+	if len(a.md5s) != len(b.md5s) {
+		return false
+	}
+
+	for _, md5 := range a.md5s {
+		if !slices.Contains(b.md5s, md5) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsAll returns true if md5s are not empty and all of them are in kept
+func containsAll(kept, md5s []string) bool {
+	if len(md5s) == 0 {
+		return false
+	}
+
+	for _, md5 := range md5s {
+		if !slices.Contains(kept, md5) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func dirSize(path string) (size int64) {
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if !d.IsDir() {
+			if info, err1 := d.Info(); err1 == nil {
+				size += info.Size()
+			}
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// DefaultQuarantineDir returns the directory redundant duplicates are moved to by default
+func DefaultQuarantineDir() string {
+	return filepath.Join(env.DataDir(), "quarantine")
+}
+
+// QuarantineDuplicates moves redundant directories to quarantineDir and removes their beatmaps from the database.
+// Returns number of moved directories and their total size.
+func QuarantineDuplicates(groups []*DuplicateGroup, quarantineDir string) (moved int, freed int64, err error) {
+	search, err := newSearchIndexUpdater(dbFile, "dir = ?")
+	if err != nil {
+		return
+	}
+
+	defer search.close()
+
+	for _, g := range groups {
+		for _, d := range g.Dirs {
+			if !d.Redundant {
+				continue
+			}
+
+			destination := filepath.Join(quarantineDir, filepath.FromSlash(d.Dir))
+
+			for i := 1; ; i++ {
+				if _, err1 := os.Stat(destination); os.IsNotExist(err1) {
+					break
+				}
+
+				destination = filepath.Join(quarantineDir, fmt.Sprintf("%s (%d)", filepath.FromSlash(d.Dir), i))
+			}
+
+			log.Println(fmt.Sprintf("DatabaseManager: Moving \"%s\" to \"%s\"...", d.Dir, destination))
+
+			if err = files.MoveDir(filepath.Join(songsDir, d.Dir), destination); err != nil {
+				return
+			}
+
+			if err = search.delete(d.Dir); err != nil {
+				return
+			}
+
+			if _, err = dbFile.Exec("DELETE FROM beatmaps WHERE dir = ?", d.Dir); err != nil {
+				return
+			}
+
+			moved++
+			freed += d.Size
+		}
+	}
+
+	return
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+)
+
+type M20261019 struct{}
+
+func (m *M20261019) RequiredSections() []string {
+	return nil
+}
+
+func (m *M20261019) FieldsToMigrate() []string {
+	return nil
+}
+
+func (m *M20261019) GetValues(_ *beatmap.BeatMap) []interface{} {
+	return nil
+}
+
+func (m *M20261019) Date() int {
+	return 20261019
+}
+
+func (m *M20261019) GetMigrationStmts() string {
+	return getSearchIndexStmts()
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+)
+
+type M20261020 struct {
+	checker *assetChecker
+}
+
+func (m *M20261020) RequiredSections() []string {
+	return []string{
+		"General",
+		"Events",
+	}
+}
+
+func (m *M20261020) FieldsToMigrate() []string {
+	return []string{
+		"assets",
+	}
+}
+
+func (m *M20261020) GetValues(beatMap *beatmap.BeatMap) []interface{} {
+	if m.checker == nil {
+		m.checker = newAssetChecker()
+	}
+
+	return []interface{}{
+		m.checker.check(beatMap),
+	}
+}
+
+func (m *M20261020) Date() int {
+	return 20261020
+}
+
+func (m *M20261020) GetMigrationStmts() string {
+	return "ALTER TABLE beatmaps ADD COLUMN assets INTEGER DEFAULT -1;" + importErrorsTableStmt
+}
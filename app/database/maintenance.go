@@ -0,0 +1,193 @@
+package database
+
+import (
+	"github.com/wieku/danser-go/framework/env"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// userData holds values that can't be recovered by parsing .osu file again
+type userData struct {
+	md5          string
+	timeAdded    int64
+	playCount    int64
+	lastPlayed   int64
+	localOffset  int
+	stars        float64
+	starsVersion int
+}
+
+// Reindex parses all .osu files again, ignoring modification times and osu!.db.
+// Play statistics, local offsets and dates of adding are kept, star rating is kept for unchanged files.
+func Reindex(importListener ImportListener) {
+	saved := loadUserData()
+
+	importMaps(false, true, nil, importListener)
+
+	restoreUserData(saved)
+}
+
+func loadUserData() map[mapLocation]userData {
+	res, err := dbFile.Query("SELECT dir, file, md5, dateAdded, playCount, lastPlayed, localOffset, stars, starsVersion FROM beatmaps")
+	if err != nil {
+		panic(err)
+	}
+
+	defer res.Close()
+
+	data := make(map[mapLocation]userData)
+
+	for res.Next() {
+		var location mapLocation
+		var d userData
+
+		if err = res.Scan(&location.dir, &location.file, &d.md5, &d.timeAdded, &d.playCount, &d.lastPlayed, &d.localOffset, &d.stars, &d.starsVersion); err != nil {
+			log.Println(err)
+			continue
+		}
+
+		data[location] = d
+	}
+
+	return data
+}
+
+func restoreUserData(data map[mapLocation]userData) {
+	tx, err := dbFile.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	st, err := tx.Prepare("UPDATE beatmaps SET dateAdded = ?, playCount = ?, lastPlayed = ?, localOffset = ? WHERE dir = ? AND file = ?")
+	if err != nil {
+		panic(err)
+	}
+
+	stStars, err := tx.Prepare("UPDATE beatmaps SET stars = ?, starsVersion = ? WHERE dir = ? AND file = ? AND md5 = ?")
+	if err != nil {
+		panic(err)
+	}
+
+	for location, d := range data {
+		if _, err1 := st.Exec(d.timeAdded, d.playCount, d.lastPlayed, d.localOffset, location.dir, location.file); err1 != nil {
+			log.Println(err1)
+		}
+
+		if d.stars >= 0 {
+			if _, err1 := stStars.Exec(d.stars, d.starsVersion, location.dir, location.file, d.md5); err1 != nil {
+				log.Println(err1)
+			}
+		}
+	}
+
+	st.Close()
+	stStars.Close()
+
+	if err = tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+// Vacuum rebuilds the database file to reclaim unused space. Returns file sizes before and after.
+func Vacuum() (before, after int64, err error) {
+	path := filepath.Join(env.DataDir(), "danser.db")
+
+	if stat, err1 := os.Stat(path); err1 == nil {
+		before = stat.Size()
+	}
+
+	if _, err = dbFile.Exec("VACUUM"); err != nil {
+		return
+	}
+
+	// VACUUM may renumber rowids the search index refers to
+	if searchAvailable {
+		if err = RebuildSearchIndex(); err != nil {
+			return
+		}
+	} else if _, err = dbFile.Exec(markSearchStaleStmt); err != nil {
+		return
+	}
+
+	if stat, err1 := os.Stat(path); err1 == nil {
+		after = stat.Size()
+	}
+
+	return
+}
+
+// LibraryStats summarizes beatmaps stored in the database
+type LibraryStats struct {
+	Beatmaps    int           `json:"beatmaps"`
+	Sets        int           `json:"sets"`
+	ByMode      map[int64]int `json:"byMode"`
+	NoStars     int           `json:"noStarRating"`
+	OldStars    int           `json:"outdatedStarRating"`
+	SearchIndex bool          `json:"searchIndex"`
+
+	// Paths relative to Songs directory
+	MissingBeatmaps    []string `json:"missingBeatmaps"`
+	MissingAudio       []string `json:"missingAudio"`
+	MissingBackgrounds []string `json:"missingBackgrounds"`
+}
+
+// GetStats counts beatmaps in the database and checks whether their files still exist
+func GetStats() *LibraryStats {
+	stats := &LibraryStats{
+		ByMode:      make(map[int64]int),
+		SearchIndex: searchAvailable,
+
+		MissingBeatmaps:    make([]string, 0),
+		MissingAudio:       make([]string, 0),
+		MissingBackgrounds: make([]string, 0),
+	}
+
+	sets := make(map[string]bool)
+
+	// Difficulties in a set usually share audio and background
+	checked := make(map[string]bool)
+
+	exists := func(path string) bool {
+		if e, ok := checked[path]; ok {
+			return e
+		}
+
+		_, err := os.Stat(filepath.Join(songsDir, path))
+		checked[path] = err == nil
+
+		return checked[path]
+	}
+
+	for _, b := range loadBeatmapsFromDatabase() {
+		stats.Beatmaps++
+		stats.ByMode[b.Mode]++
+
+		sets[b.Dir] = true
+
+		if b.Mode == 0 {
+			if b.Stars < 0 {
+				stats.NoStars++
+			} else if b.StarsVersion < difficultyCalc.GetVersion() {
+				stats.OldStars++
+			}
+		}
+
+		if !exists(filepath.Join(b.Dir, b.File)) {
+			stats.MissingBeatmaps = append(stats.MissingBeatmaps, b.Dir+"/"+b.File)
+			continue
+		}
+
+		if b.Audio != "" && !exists(filepath.Join(b.Dir, b.Audio)) {
+			stats.MissingAudio = append(stats.MissingAudio, b.Dir+"/"+b.File)
+		}
+
+		if b.Bg != "" && !exists(filepath.Join(b.Dir, b.Bg)) {
+			stats.MissingBackgrounds = append(stats.MissingBackgrounds, b.Dir+"/"+b.File)
+		}
+	}
+
+	stats.Sets = len(sets)
+
+	return stats
+}
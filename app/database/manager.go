@@ -7,6 +7,7 @@ import (
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp250306"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/utils"
@@ -22,12 +23,13 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 var dbFile *sql.DB
 
-const databaseVersion = 20220622
+const databaseVersion = 20261020
 
 var currentPreVersion = databaseVersion
 var currentSchemaPreVersion = databaseVersion
@@ -74,6 +76,9 @@ func Init() error {
 		&M20210423{},
 		&M20220605{},
 		&M20220622{},
+		&M20261018{},
+		&M20261019{},
+		&M20261020{},
 	}
 
 	dbFile, err = sql.Open("sqlite3", filepath.Join(env.DataDir(), "danser.db"))
@@ -81,11 +86,13 @@ func Init() error {
 		return err
 	}
 
+	checkSearchSupport()
+
 	_, err = dbFile.Exec(`
-		CREATE TABLE IF NOT EXISTS beatmaps (dir TEXT, file TEXT, lastModified INTEGER, title TEXT, titleUnicode TEXT, artist TEXT, artistUnicode TEXT, creator TEXT, version TEXT, source TEXT, tags TEXT, cs REAL, ar REAL, sliderMultiplier REAL, sliderTickRate REAL, audioFile TEXT, previewTime INTEGER, sampleSet INTEGER, stackLeniency REAL, mode INTEGER, bg TEXT, md5 TEXT, dateAdded INTEGER, playCount INTEGER, lastPlayed INTEGER, hpdrain REAL, od REAL, stars REAL DEFAULT -1, bpmMin REAL, bpmMax REAL, circles INTEGER, sliders INTEGER, spinners INTEGER, endTime INTEGER, setID INTEGER, mapID INTEGER, starsVersion INTEGER DEFAULT 0, localOffset INTEGER DEFAULT 0);
+		CREATE TABLE IF NOT EXISTS beatmaps (dir TEXT, file TEXT, lastModified INTEGER, title TEXT, titleUnicode TEXT, artist TEXT, artistUnicode TEXT, creator TEXT, version TEXT, source TEXT, tags TEXT, cs REAL, ar REAL, sliderMultiplier REAL, sliderTickRate REAL, audioFile TEXT, previewTime INTEGER, sampleSet INTEGER, stackLeniency REAL, mode INTEGER, bg TEXT, md5 TEXT, dateAdded INTEGER, playCount INTEGER, lastPlayed INTEGER, hpdrain REAL, od REAL, stars REAL DEFAULT -1, bpmMin REAL, bpmMax REAL, circles INTEGER, sliders INTEGER, spinners INTEGER, endTime INTEGER, setID INTEGER, mapID INTEGER, starsVersion INTEGER DEFAULT 0, localOffset INTEGER DEFAULT 0, assets INTEGER DEFAULT -1);
 		CREATE INDEX IF NOT EXISTS idx ON beatmaps (dir, file);
 		CREATE TABLE IF NOT EXISTS info (key TEXT NOT NULL UNIQUE, value TEXT);
-	`)
+	` + importErrorsTableStmt + dropSearchTriggersStmt)
 
 	if err != nil {
 		return err
@@ -147,6 +154,10 @@ func Init() error {
 		return err
 	}
 
+	if err = ensureSearchIndex(); err != nil {
+		return err
+	}
+
 	if currentPreVersion != databaseVersion {
 		migrateBeatmaps()
 	}
@@ -160,28 +171,43 @@ func Init() error {
 }
 
 func LoadBeatmaps(skipDatabaseCheck bool, importListener ImportListener) []*beatmap.BeatMap {
+	return LoadBeatmapsMode(skipDatabaseCheck, importListener, 0)
+}
+
+// LoadBeatmapsMode imports new beatmaps and returns the ones made for given game modes
+func LoadBeatmapsMode(skipDatabaseCheck bool, importListener ImportListener, modes ...int64) []*beatmap.BeatMap {
+	ImportBeatmaps(skipDatabaseCheck, importListener)
+
+	return GetBeatmaps(modes...)
+}
+
+// ImportBeatmaps unpacks .osz files if enabled and imports new or changed beatmaps
+func ImportBeatmaps(skipDatabaseCheck bool, importListener ImportListener) {
 	var unpackedMaps []string
 	if settings.General.UnpackOszFiles {
 		unpackedMaps = unpackMaps()
 	}
 
-	importMaps(skipDatabaseCheck, unpackedMaps, importListener)
+	importMaps(skipDatabaseCheck, false, unpackedMaps, importListener)
+}
 
+// GetBeatmaps returns beatmaps made for given game modes that are already in the database
+func GetBeatmaps(modes ...int64) []*beatmap.BeatMap {
 	log.Println("DatabaseManager: Loading beatmaps from database...")
 
 	allMaps := loadBeatmapsFromDatabase()
 
-	stdMaps := make([]*beatmap.BeatMap, 0, len(allMaps)/2)
+	modeMaps := make([]*beatmap.BeatMap, 0, len(allMaps)/2)
 
 	for _, b := range allMaps {
-		if b.Mode == 0 {
-			stdMaps = append(stdMaps, b)
+		if slices.Contains(modes, b.Mode) {
+			modeMaps = append(modeMaps, b)
 		}
 	}
 
-	log.Println("DatabaseManager: Loaded", len(stdMaps), "total.")
+	log.Println("DatabaseManager: Loaded", len(modeMaps), "total.")
 
-	return stdMaps
+	return modeMaps
 }
 
 func unpackMaps() (dirs []string) {
@@ -217,7 +243,8 @@ const (
 	Finished
 )
 
-func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener ImportListener) {
+// importMaps imports new and changed beatmaps, with forceParse every .osu file is parsed again
+func importMaps(skipDatabaseCheck, forceParse bool, mustCheckDirs []string, importListener ImportListener) {
 	const workers = 4
 
 	cachedFolders, mapsInDB := getLastModified()
@@ -273,19 +300,43 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 
 	log.Println("DatabaseManager: Scan complete. Found", len(candidates), "files.")
 
+	var existing map[mapLocation]bool
+
+	if !skipDatabaseCheck {
+		existing = make(map[mapLocation]bool, len(candidates))
+
+		for _, c := range candidates {
+			existing[c.location] = true
+		}
+	}
+
+	var attempted []mapLocation
+	var importErrors []ImportError
+	var errorsMutex sync.Mutex
+
+	addImportError := func(location mapLocation, err any) {
+		errorsMutex.Lock()
+		importErrors = append(importErrors, newImportError(location, err))
+		errorsMutex.Unlock()
+	}
+
+	defer func() {
+		saveImportErrors(attempted, existing, importErrors)
+	}()
+
 	if len(candidates) == 0 {
 		return
 	}
 
 	log.Println("DatabaseManager: Comparing files with database...")
 
-	mapsToImport := make([]mapLocation, 0)
+	changedMaps := make([]modMap, 0)
 
 	trySendStatus(importListener, Comparison, 0, 0)
 
 	for _, candidate := range candidates {
 		if lastModified, ok := mapsInDB[candidate.location]; ok {
-			if lastModified == candidate.modTime.UnixNano()/1000000 {
+			if !forceParse && lastModified == candidate.modTime.UnixNano()/1000000 {
 				// Map is up-to-date, so remove it from mapsInDB because values left in that map are later removed from database.
 				delete(mapsInDB, candidate.location)
 
@@ -299,7 +350,7 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 			log.Println("DatabaseManager: New beatmap found:", candidate.location.file)
 		}
 
-		mapsToImport = append(mapsToImport, candidate.location)
+		changedMaps = append(changedMaps, candidate)
 	}
 
 	log.Println("DatabaseManager: Compare complete.")
@@ -320,10 +371,26 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 		log.Println("DatabaseManager: Removal complete.")
 	}
 
-	if len(mapsToImport) == 0 {
+	for _, m := range changedMaps {
+		attempted = append(attempted, m.location)
+	}
+
+	checker := newAssetChecker()
+
+	if len(changedMaps) > 0 && settings.General.ImportOsuDatabase && !forceParse {
+		changedMaps = importFromOsuDB(changedMaps, checker, importListener)
+	}
+
+	if len(changedMaps) == 0 {
 		return
 	}
 
+	mapsToImport := make([]mapLocation, 0, len(changedMaps))
+
+	for _, m := range changedMaps {
+		mapsToImport = append(mapsToImport, m.location)
+	}
+
 	log.Println("DatabaseManager: Starting import of", len(mapsToImport), "maps. It may take up to several minutes...")
 
 	trySendStatus(importListener, Import, 0, len(mapsToImport))
@@ -335,6 +402,8 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 			defer func() {
 				if err := recover(); err != nil { //TODO: Technically should be fixed but unexpected parsing problem won't crash whole process
 					log.Println("DatabaseManager: Failed to load \"", candidate.dir+"/"+candidate.file, "\":", err)
+
+					addImportError(candidate, err)
 				}
 			}()
 
@@ -344,6 +413,8 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 			file, err := os.Open(mapPath)
 			if err != nil {
 				log.Println(fmt.Sprintf("\"DatabaseManager: Failed to read \"%s\", skipping. Error: %s", partialPath, err))
+				addImportError(candidate, err)
+
 				return nil, false
 			}
 
@@ -353,7 +424,7 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 				log.Println("DatabaseManager: Importing:", partialPath)
 			}
 
-			if bMap := beatmap.ParseBeatMapFile(file); bMap != nil {
+			if bMap, err := beatmap.TryParseBeatMapFile(file); err == nil {
 				stat, _ := file.Stat()
 				bMap.LastModified = stat.ModTime().UnixNano() / 1000000
 				bMap.TimeAdded = time.Now().UnixNano() / 1000000
@@ -363,13 +434,16 @@ func importMaps(skipDatabaseCheck bool, mustCheckDirs []string, importListener I
 					bMap.MD5 = hex.EncodeToString(hash.Sum(nil))
 				}
 
+				bMap.Assets = checker.check(bMap)
+
 				if settings.General.VerboseImportLogs {
 					log.Println("DatabaseManager: Imported:", partialPath)
 				}
 
 				return bMap, true
 			} else {
-				log.Println("DatabaseManager: Failed to import:", partialPath)
+				log.Println("DatabaseManager: Failed to import:", partialPath, err)
+				addImportError(candidate, err)
 			}
 
 			return nil, false
@@ -413,13 +487,28 @@ func trySendStatus(listener ImportListener, stage ImportStage, progress, target
 	}
 }
 
+// StarRatingVersion returns the version of star rating calculator used by UpdateStarRating
+func StarRatingVersion() int {
+	return difficultyCalc.GetVersion()
+}
+
+// UpdateStarRating calculates star rating of osu!standard beatmaps that don't have it yet or were calculated with an older version
 func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed, target int, message string)) {
+	calculateStarRating(maps, difficultyCalc, false, progressListener)
+}
+
+// RecalculateStarRating calculates star rating of all given osu!standard beatmaps using provided calculator
+func RecalculateStarRating(maps []*beatmap.BeatMap, calculator api.IDifficultyCalculator, progressListener func(processed, target int, message string)) {
+	calculateStarRating(maps, calculator, true, progressListener)
+}
+
+func calculateStarRating(maps []*beatmap.BeatMap, calculator api.IDifficultyCalculator, force bool, progressListener func(processed, target int, message string)) {
 	const workers = 1 // For now using only one thread because calculating 4 aspire maps at once can OOM since (de)allocation can't keep up with many complex sliders
 
 	var toCalculate []*beatmap.BeatMap
 
 	for _, b := range maps {
-		if b.Mode == 0 && (b.Stars < 0 || b.StarsVersion < difficultyCalc.GetVersion()) {
+		if b.Mode == 0 && (force || b.Stars < 0 || b.StarsVersion < calculator.GetVersion()) {
 			toCalculate = append(toCalculate, b)
 		}
 	}
@@ -451,7 +540,7 @@ func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed,
 			ret2 = true
 
 			defer func() {
-				bMap.StarsVersion = difficultyCalc.GetVersion()
+				bMap.StarsVersion = calculator.GetVersion()
 				bMap.Clear() //Clear objects and timing to avoid OOM
 
 				if err := recover(); err != nil { //TODO: Technically should be fixed but unexpected parsing problem won't crash whole process
@@ -467,7 +556,7 @@ func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed,
 				log.Println("DatabaseManager:", bMap.Dir+"/"+bMap.File, "doesn't have enough hitobjects")
 				bMap.Stars = 0
 			} else {
-				attr := difficultyCalc.CalculateSingle(bMap.HitObjects, bMap.Diff)
+				attr := calculator.CalculateSingle(bMap.HitObjects, bMap.Diff)
 				bMap.Stars = attr.Total
 			}
 
@@ -557,19 +646,28 @@ func removeBeatmaps(toRemove []mapLocation) {
 
 	if err == nil {
 		st, err := tx.Prepare("DELETE FROM beatmaps WHERE dir = ? AND file = ?")
+		if err != nil {
+			panic(err)
+		}
 
-		if err == nil {
-			for _, bMap := range toRemove {
-				_, err1 := st.Exec(bMap.dir, bMap.file)
+		search, err := newSearchIndexUpdater(tx, "dir = ? AND file = ?")
+		if err != nil {
+			panic(err)
+		}
 
-				if err1 != nil {
-					log.Println(err1)
-				}
+		for _, bMap := range toRemove {
+			if err1 := search.delete(bMap.dir, bMap.file); err1 != nil {
+				log.Println(err1)
+			}
+
+			_, err1 := st.Exec(bMap.dir, bMap.file)
+
+			if err1 != nil {
+				log.Println(err1)
 			}
-		} else {
-			panic(err)
 		}
 
+		search.close()
 		st.Close()
 		tx.Commit()
 	}
@@ -670,6 +768,15 @@ func migrateBeatmaps() {
 			if err != nil {
 				panic(err)
 			}
+
+			// Migrations may change indexed text fields
+			if searchAvailable {
+				if err = RebuildSearchIndex(); err != nil {
+					log.Println("DatabaseManager: Failed to rebuild search index:", err)
+				}
+			} else if _, err = dbFile.Exec(markSearchStaleStmt); err != nil {
+				log.Println(err)
+			}
 		}
 	}
 
@@ -681,11 +788,16 @@ func insertBeatmaps(bMaps []*beatmap.BeatMap) {
 
 	if err == nil {
 		var st *sql.Stmt
-		st, err = tx.Prepare("INSERT INTO beatmaps VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		st, err = tx.Prepare("INSERT INTO beatmaps VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		var search *searchIndexUpdater
+		if err == nil {
+			search, err = newSearchIndexUpdater(tx, "rowid = ?")
+		}
 
 		if err == nil {
 			for _, bMap := range bMaps {
-				_, err1 := st.Exec(
+				res, err1 := st.Exec(
 					bMap.Dir,
 					bMap.File,
 					bMap.LastModified,
@@ -724,8 +836,14 @@ func insertBeatmaps(bMaps []*beatmap.BeatMap) {
 					bMap.ID,
 					bMap.StarsVersion,
 					bMap.LocalOffset,
+					bMap.Assets,
 				)
 
+				if err1 == nil {
+					rowID, _ := res.LastInsertId()
+					err1 = search.add(rowID)
+				}
+
 				if err1 != nil {
 					log.Println(err1)
 				}
@@ -734,6 +852,7 @@ func insertBeatmaps(bMaps []*beatmap.BeatMap) {
 			panic(err)
 		}
 
+		search.close()
 		st.Close()
 		tx.Commit()
 	}
@@ -792,6 +911,7 @@ func loadBeatmapsFromDatabase() []*beatmap.BeatMap {
 			&beatMap.ID,
 			&beatMap.StarsVersion,
 			&beatMap.LocalOffset,
+			&beatMap.Assets,
 		)
 
 		beatMap.Diff.SetCS(mutils.Clamp(cs, 0, 10))
@@ -838,4 +958,6 @@ func Close() {
 
 		dbFile = nil
 	}
+
+	closeScoresDB()
 }
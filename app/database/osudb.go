@@ -0,0 +1,364 @@
+package database
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/settings"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// osu!.db versions that changed the layout of beatmap entries
+	osuDBFloatDifficulty = 20140609
+	osuDBNoEntrySize     = 20191106
+	osuDBFloatStars      = 20250107
+
+	// Ticks between 0001-01-01 and unix epoch, DateTime in .NET counts 100ns ticks from the former
+	windowsTicksEpoch = 621355968000000000
+
+	// Maximum difference between file's and osu!.db's modification time to consider the entry up-to-date
+	osuDBTimeTolerance = 2000
+)
+
+type osuDBReader struct {
+	reader  *bufio.Reader
+	version int32
+	err     error
+}
+
+func (r *osuDBReader) read(data any) {
+	if r.err == nil {
+		r.err = binary.Read(r.reader, binary.LittleEndian, data)
+	}
+}
+
+func (r *osuDBReader) skip(n int) {
+	if r.err == nil {
+		_, r.err = r.reader.Discard(n)
+	}
+}
+
+func (r *osuDBReader) byte() (v uint8) {
+	r.read(&v)
+	return
+}
+
+func (r *osuDBReader) bool() bool {
+	return r.byte() != 0
+}
+
+func (r *osuDBReader) short() (v int16) {
+	r.read(&v)
+	return
+}
+
+func (r *osuDBReader) int() (v int32) {
+	r.read(&v)
+	return
+}
+
+func (r *osuDBReader) long() (v int64) {
+	r.read(&v)
+	return
+}
+
+func (r *osuDBReader) single() (v float32) {
+	r.read(&v)
+	return
+}
+
+func (r *osuDBReader) double() (v float64) {
+	r.read(&v)
+	return
+}
+
+// string reads .NET string: 0x00 if it's absent, 0x0b followed by ULEB128 length and UTF-8 bytes otherwise
+func (r *osuDBReader) string() string {
+	switch r.byte() {
+	case 0x00:
+		return ""
+	case 0x0b:
+	default:
+		if r.err == nil {
+			r.err = errors.New("invalid string marker")
+		}
+
+		return ""
+	}
+
+	if r.err != nil {
+		return ""
+	}
+
+	length, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		r.err = err
+		return ""
+	}
+
+	data := make([]byte, length)
+
+	if _, err = io.ReadFull(r.reader, data); err != nil {
+		r.err = err
+		return ""
+	}
+
+	return string(data)
+}
+
+// difficulty reads AR, CS, HP or OD which were stored as bytes in older versions
+func (r *osuDBReader) difficulty() float64 {
+	if r.version < osuDBFloatDifficulty {
+		return float64(r.byte())
+	}
+
+	return float64(r.single())
+}
+
+// skipStarRatings skips cached star ratings of a single game mode, danser calculates its own
+func (r *osuDBReader) skipStarRatings() {
+	pairSize := 14 // 0x08, int mods, 0x0d, double stars
+	if r.version >= osuDBFloatStars {
+		pairSize = 10 // 0x08, int mods, 0x0c, single stars
+	}
+
+	count := r.int()
+	if count < 0 {
+		r.err = errors.New("invalid star rating count")
+		return
+	}
+
+	r.skip(int(count) * pairSize)
+}
+
+func ticksToMillis(ticks int64) int64 {
+	return (ticks - windowsTicksEpoch) / 10000
+}
+
+// osuDir returns osu!stable's installation directory which is the parent of the Songs directory
+func osuDir() string {
+	return filepath.Dir(settings.General.GetSongsDir())
+}
+
+// osuDBPath returns the location of osu!.db
+func osuDBPath() string {
+	return filepath.Join(osuDir(), "osu!.db")
+}
+
+// readOsuDB reads beatmap entries of osu!stable's osu!.db, keyed by their location in Songs directory
+func readOsuDB(path string) (map[mapLocation]*beatmap.BeatMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	r := &osuDBReader{reader: bufio.NewReaderSize(file, 1024*1024)}
+
+	r.version = r.int()
+	r.int()    // folder count
+	r.bool()   // account unlocked
+	r.long()   // account unlock date
+	r.string() // player name
+
+	count := r.int()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	maps := make(map[mapLocation]*beatmap.BeatMap, max(count, 0))
+
+	for i := int32(0); i < count; i++ {
+		if bMap := r.readBeatmap(); bMap != nil {
+			maps[mapLocation{
+				dir:  bMap.Dir,
+				file: bMap.File,
+			}] = bMap
+		}
+
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to read beatmap %d of %d: %w", i+1, count, r.err)
+		}
+	}
+
+	return maps, nil
+}
+
+func (r *osuDBReader) readBeatmap() *beatmap.BeatMap {
+	if r.version < osuDBNoEntrySize {
+		r.int() // entry size in bytes
+	}
+
+	bMap := beatmap.NewBeatMap()
+
+	bMap.Artist = r.string()
+	bMap.ArtistUnicode = r.string()
+	bMap.Name = r.string()
+	bMap.NameUnicode = r.string()
+	bMap.Creator = r.string()
+	bMap.Difficulty = r.string()
+	bMap.Audio = r.string()
+	bMap.MD5 = r.string()
+	bMap.File = r.string()
+
+	r.byte() // ranked status
+
+	bMap.Circles = int(uint16(r.short()))
+	bMap.Sliders = int(uint16(r.short()))
+	bMap.Spinners = int(uint16(r.short()))
+
+	bMap.LastModified = ticksToMillis(r.long())
+
+	bMap.Diff.SetAR(r.difficulty())
+	bMap.Diff.SetCS(r.difficulty())
+	bMap.Diff.SetHP(r.difficulty())
+	bMap.Diff.SetOD(r.difficulty())
+
+	bMap.SliderMultiplier = r.double()
+	bMap.Timings.SliderMult = bMap.SliderMultiplier
+
+	if r.version >= osuDBFloatDifficulty {
+		for range 4 {
+			r.skipStarRatings()
+		}
+	}
+
+	r.int() // drain time in seconds
+
+	bMap.Length = int(r.int())
+	bMap.PreviewTime = int64(r.int())
+
+	points := r.int()
+	if points < 0 {
+		r.err = errors.New("invalid timing point count")
+		return nil
+	}
+
+	for range points {
+		beatLength := r.double()
+		r.double() // offset
+		r.bool()   // uninherited
+
+		if !math.IsNaN(beatLength) && beatLength >= 0 {
+			bpm := 60000 / beatLength
+			bMap.MinBPM = min(bMap.MinBPM, bpm)
+			bMap.MaxBPM = max(bMap.MaxBPM, bpm)
+		}
+	}
+
+	bMap.ID = int64(r.int())
+	bMap.SetID = int64(r.int())
+
+	r.int()   // thread ID
+	r.skip(4) // grades achieved in each mode
+	r.short() // local offset
+	bMap.StackLeniency = float64(r.single())
+	bMap.Mode = int64(r.byte())
+	bMap.Source = r.string()
+	bMap.Tags = r.string()
+	r.short()  // online offset
+	r.string() // title font
+	r.bool()   // unplayed
+	r.long()   // last played
+	r.bool()   // osz2
+
+	bMap.Dir = filepath.ToSlash(strings.TrimSpace(r.string()))
+
+	r.long()  // last online check
+	r.skip(5) // ignore sounds, ignore skin, disable storyboard, disable video, visual override
+
+	if r.version < osuDBFloatDifficulty {
+		r.short()
+	}
+
+	r.int()  // last modification time
+	r.byte() // mania scroll speed
+
+	if r.err != nil || bMap.Dir == "" || bMap.File == "" {
+		return nil
+	}
+
+	return bMap
+}
+
+// importFromOsuDB seeds the database with entries from osu!.db that match the files on disk.
+// Returns candidates that are missing from osu!.db or changed since osu!stable last saw them.
+func importFromOsuDB(candidates []modMap, checker *assetChecker, importListener ImportListener) []modMap {
+	path := osuDBPath()
+
+	if _, err := os.Stat(path); err != nil {
+		return candidates
+	}
+
+	log.Println(fmt.Sprintf("DatabaseManager: Reading \"%s\"...", path))
+
+	osuMaps, err := readOsuDB(path)
+	if err != nil {
+		log.Println("DatabaseManager: Failed to read osu!.db, falling back to parsing .osu files:", err)
+		return candidates
+	}
+
+	log.Println("DatabaseManager: Found", len(osuMaps), "beatmaps in osu!.db.")
+
+	remaining := make([]modMap, 0)
+
+	var numImported int
+	var imported []*beatmap.BeatMap
+
+	trySendStatus(importListener, Import, 0, len(candidates))
+
+	for i, candidate := range candidates {
+		trySendStatus(importListener, Import, i+1, len(candidates))
+
+		bMap, ok := osuMaps[candidate.location]
+
+		modTime := candidate.modTime.UnixNano() / 1000000
+
+		if !ok || bMap.MD5 == "" || max(modTime-bMap.LastModified, bMap.LastModified-modTime) > osuDBTimeTolerance {
+			remaining = append(remaining, candidate)
+			continue
+		}
+
+		if err = beatmap.ParseHeaderExtras(bMap); err != nil {
+			remaining = append(remaining, candidate)
+			continue
+		}
+
+		bMap.LastModified = modTime
+		bMap.TimeAdded = time.Now().UnixNano() / 1000000
+		bMap.Assets = checker.check(bMap)
+
+		if settings.General.VerboseImportLogs {
+			log.Println("DatabaseManager: Imported from osu!.db:", bMap.Dir+"/"+bMap.File)
+		}
+
+		numImported++
+
+		imported = append(imported, bMap)
+
+		if len(imported) >= 1000 {
+			insertBeatmaps(imported)
+
+			imported = imported[:0]
+		}
+	}
+
+	if len(imported) > 0 {
+		insertBeatmaps(imported)
+	}
+
+	log.Println("DatabaseManager: Imported", numImported, "beatmaps from osu!.db,", len(remaining), "left to parse.")
+
+	return remaining
+}
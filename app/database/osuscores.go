@@ -0,0 +1,162 @@
+package database
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// replayFileEpoch converts .NET ticks to file time used in names of replays in Data/r
+const replayFileEpoch = 504911232000000000
+
+// OsuScore is a local play stored in osu!stable's scores.db
+type OsuScore struct {
+	Mode       int8
+	Version    int32
+	BeatmapMD5 string
+	Player     string
+	ReplayMD5  string
+
+	Count300  int16
+	Count100  int16
+	Count50   int16
+	CountGeki int16
+	CountKatu int16
+	CountMiss int16
+
+	Score    int32
+	MaxCombo int16
+	Perfect  bool
+	Mods     difficulty.Modifier
+
+	// ticks is the raw .NET timestamp, it's needed to find the replay file
+	ticks     int64
+	Timestamp time.Time
+
+	OnlineID int64
+}
+
+// OsuScoresPath returns the location of osu!stable's scores.db
+func OsuScoresPath() string {
+	return filepath.Join(osuDir(), "scores.db")
+}
+
+// ReadOsuScores parses osu!stable's scores.db, scores are grouped by lowercase beatmap MD5
+func ReadOsuScores(path string) (map[string][]*OsuScore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	r := &osuDBReader{reader: bufio.NewReaderSize(file, 1024*1024)}
+
+	r.version = r.int()
+
+	count := r.int()
+	if count < 0 {
+		return nil, errors.New("invalid beatmap count")
+	}
+
+	scores := make(map[string][]*OsuScore, count)
+
+	for range count {
+		md5 := strings.ToLower(r.string())
+
+		scoreCount := r.int()
+		if scoreCount < 0 {
+			return nil, errors.New("invalid score count")
+		}
+
+		for range scoreCount {
+			score := r.readScore()
+
+			if r.err != nil {
+				return nil, r.err
+			}
+
+			scores[md5] = append(scores[md5], score)
+		}
+	}
+
+	return scores, r.err
+}
+
+func (r *osuDBReader) readScore() *OsuScore {
+	score := &OsuScore{
+		Mode:       int8(r.byte()),
+		Version:    r.int(),
+		BeatmapMD5: r.string(),
+		Player:     r.string(),
+		ReplayMD5:  r.string(),
+		Count300:   r.short(),
+		Count100:   r.short(),
+		Count50:    r.short(),
+		CountGeki:  r.short(),
+		CountKatu:  r.short(),
+		CountMiss:  r.short(),
+		Score:      r.int(),
+		MaxCombo:   r.short(),
+		Perfect:    r.bool(),
+		Mods:       difficulty.Modifier(uint32(r.int())),
+	}
+
+	r.string() // life bar graph, always empty
+
+	score.ticks = r.long()
+	score.Timestamp = time.UnixMilli(ticksToMillis(score.ticks))
+
+	r.int() // always -1
+
+	score.OnlineID = r.long()
+
+	if score.Mods.Active(difficulty.Target) {
+		r.double() // total accuracy of all hits
+	}
+
+	return score
+}
+
+// FindReplay returns path to the replay of the score in osu!'s Data/r directory, or empty string if it's missing.
+// Replays are named after the beatmap MD5 and file time of the play, if that fails, replays of the beatmap are matched by their MD5.
+func (score *OsuScore) FindReplay() string {
+	replayDir := filepath.Join(osuDir(), "Data", "r")
+
+	path := filepath.Join(replayDir, fmt.Sprintf("%s-%d.osr", score.BeatmapMD5, score.ticks-replayFileEpoch))
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	if score.ReplayMD5 == "" {
+		return ""
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(replayDir, score.BeatmapMD5+"-*.osr"))
+
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			continue
+		}
+
+		replay, err := rplpa.ParseReplay(data)
+		if err != nil {
+			log.Println("Failed to parse replay", match, err)
+			continue
+		}
+
+		if strings.EqualFold(replay.ReplayMD5, score.ReplayMD5) {
+			return match
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/wieku/danser-go/framework/env"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const scoresTableStmt = `
+		CREATE TABLE IF NOT EXISTS scores (id INTEGER PRIMARY KEY AUTOINCREMENT, beatmapMD5 TEXT, player TEXT, source INTEGER, mods TEXT, legacyMods INTEGER, score INTEGER, accuracy REAL, grade TEXT, maxCombo INTEGER, perfect INTEGER, count300 INTEGER, countGeki INTEGER, count100 INTEGER, countKatu INTEGER, count50 INTEGER, countMiss INTEGER, sliderBreaks INTEGER, pp REAL, ppVersion TEXT, ur REAL, date INTEGER, replay TEXT, UNIQUE (beatmapMD5, player, date));
+		CREATE INDEX IF NOT EXISTS scoresIdx ON scores (beatmapMD5);`
+
+// Scores are saved and loaded from background goroutines, so they use their own connection instead of dbFile
+var scoresDB *sql.DB
+var scoresMutex sync.Mutex
+
+type ScoreSource int
+
+const (
+	SourcePlay = ScoreSource(iota)
+	SourceReplay
+)
+
+// Score is a result of a local play or a watched replay
+type Score struct {
+	ID         int64
+	BeatmapMD5 string
+	Player     string
+	Source     ScoreSource
+
+	Mods       string
+	LegacyMods int64
+
+	Score        int64
+	Accuracy     float64
+	Grade        string
+	MaxCombo     int64
+	PerfectCombo bool
+
+	Count300    int64
+	CountGeki   int64
+	Count100    int64
+	CountKatu   int64
+	Count50     int64
+	CountMiss   int64
+	SliderBreak int64
+
+	PP        float64
+	PPVersion string
+	UR        float64
+
+	Date   time.Time
+	Replay string // Path to the replay file, can be empty
+}
+
+// SaveScore adds the score to the database. Score with the same beatmap, player and date is stored only once.
+// It's safe to call from any goroutine.
+func SaveScore(score *Score) error {
+	db, err := getScoresDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(
+		"INSERT OR IGNORE INTO scores (beatmapMD5, player, source, mods, legacyMods, score, accuracy, grade, maxCombo, perfect, count300, countGeki, count100, countKatu, count50, countMiss, sliderBreaks, pp, ppVersion, ur, date, replay) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		score.BeatmapMD5,
+		score.Player,
+		score.Source,
+		score.Mods,
+		score.LegacyMods,
+		score.Score,
+		score.Accuracy,
+		score.Grade,
+		score.MaxCombo,
+		score.PerfectCombo,
+		score.Count300,
+		score.CountGeki,
+		score.Count100,
+		score.CountKatu,
+		score.Count50,
+		score.CountMiss,
+		score.SliderBreak,
+		score.PP,
+		score.PPVersion,
+		score.UR,
+		score.Date.UnixMilli(),
+		score.Replay,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	score.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// GetScores returns all scores set on the beatmap, best first
+func GetScores(beatmapMD5 string) ([]*Score, error) {
+	return queryScores("SELECT * FROM scores WHERE beatmapMD5 = ? ORDER BY score DESC, date ASC", beatmapMD5)
+}
+
+// GetPersonalBests returns the best score of every player on the beatmap, best first
+func GetPersonalBests(beatmapMD5 string) ([]*Score, error) {
+	scores, err := GetScores(beatmapMD5)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make(map[string]struct{})
+	bests := make([]*Score, 0)
+
+	for _, s := range scores {
+		if _, ok := players[s.Player]; ok {
+			continue
+		}
+
+		players[s.Player] = struct{}{}
+		bests = append(bests, s)
+	}
+
+	return bests, nil
+}
+
+// GetHistory returns scores set by the player, newest first. Empty player returns scores of all players
+func GetHistory(player string, limit int) ([]*Score, error) {
+	if player == "" {
+		return queryScores("SELECT * FROM scores ORDER BY date DESC LIMIT ?", limit)
+	}
+
+	return queryScores("SELECT * FROM scores WHERE player = ? ORDER BY date DESC LIMIT ?", player, limit)
+}
+
+func queryScores(query string, args ...any) ([]*Score, error) {
+	db, err := getScoresDB()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	scores := make([]*Score, 0)
+
+	for res.Next() {
+		score := new(Score)
+
+		var date int64
+
+		err = res.Scan(
+			&score.ID,
+			&score.BeatmapMD5,
+			&score.Player,
+			&score.Source,
+			&score.Mods,
+			&score.LegacyMods,
+			&score.Score,
+			&score.Accuracy,
+			&score.Grade,
+			&score.MaxCombo,
+			&score.PerfectCombo,
+			&score.Count300,
+			&score.CountGeki,
+			&score.Count100,
+			&score.CountKatu,
+			&score.Count50,
+			&score.CountMiss,
+			&score.SliderBreak,
+			&score.PP,
+			&score.PPVersion,
+			&score.UR,
+			&date,
+			&score.Replay,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		score.Date = time.UnixMilli(date)
+
+		scores = append(scores, score)
+	}
+
+	return scores, res.Err()
+}
+
+// getScoresDB opens the connection used for scores on first use. The scores table is created by migrations in Init.
+func getScoresDB() (*sql.DB, error) {
+	scoresMutex.Lock()
+	defer scoresMutex.Unlock()
+
+	if scoresDB != nil {
+		return scoresDB, nil
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(env.DataDir(), "danser.db")+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	scoresDB = db
+
+	return scoresDB, nil
+}
+
+func closeScoresDB() {
+	scoresMutex.Lock()
+	defer scoresMutex.Unlock()
+
+	if scoresDB != nil {
+		if err := scoresDB.Close(); err != nil {
+			log.Println("Failed to close scores database:", err)
+		}
+
+		scoresDB = nil
+	}
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// searchIndexStmt creates FTS5 index over text fields of beatmaps.
+// Index uses beatmaps' rowids, so it has to be rebuilt if they change (e.g. after VACUUM).
+// It's kept in sync by searchIndexUpdater and not by triggers, because triggers would break writes to beatmaps table in builds without FTS5.
+const searchIndexStmt = `
+		CREATE VIRTUAL TABLE IF NOT EXISTS beatmaps_fts USING fts5(title, titleUnicode, artist, artistUnicode, creator, source, tags, version, content='beatmaps', tokenize='unicode61 remove_diacritics 2', prefix='2 3');`
+
+// dropSearchTriggersStmt removes triggers created by earlier versions of the search index
+const dropSearchTriggersStmt = `
+		DROP TRIGGER IF EXISTS beatmaps_fts_ai;
+		DROP TRIGGER IF EXISTS beatmaps_fts_ad;
+		DROP TRIGGER IF EXISTS beatmaps_fts_au;`
+
+const searchColumns = "title, titleUnicode, artist, artistUnicode, creator, source, tags, version"
+
+// markSearchStaleStmt is executed when beatmaps change in a build without FTS5, so the next build with FTS5 rebuilds the index
+const markSearchStaleStmt = "REPLACE INTO info (key, value) VALUES ('search_index', 'stale');"
+
+const clearSearchStaleStmt = "DELETE FROM info WHERE key = 'search_index';"
+
+const searchRebuildStmt = "INSERT INTO beatmaps_fts (beatmaps_fts) VALUES ('rebuild');"
+
+// Column weights used in ranking, matches in title and artist are more important than in tags
+const searchRankStmt = "bm25(beatmaps_fts, 10, 10, 8, 8, 5, 3, 2, 4)"
+
+// searchAvailable is true if sqlite was built with FTS5 (sqlite_fts5 build tag)
+var searchAvailable bool
+
+// checkSearchSupport checks whether FTS5 is compiled in, must be called before migrations
+func checkSearchSupport() {
+	var used bool
+
+	if err := dbFile.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used); err != nil || !used {
+		log.Println("DatabaseManager: SQLite was built without FTS5, full-text search is disabled. Build with \"sqlite_fts5\" tag to enable it.")
+
+		searchAvailable = false
+
+		return
+	}
+
+	searchAvailable = true
+}
+
+// getSearchIndexStmts returns statements creating and filling the search index, empty if FTS5 is not available
+func getSearchIndexStmts() string {
+	if !searchAvailable {
+		return ""
+	}
+
+	return searchIndexStmt + searchRebuildStmt
+}
+
+// ensureSearchIndex creates the index in new databases or ones that were migrated by a build without FTS5
+func ensureSearchIndex() error {
+	if !searchAvailable {
+		return nil
+	}
+
+	var count int
+
+	if err := dbFile.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'beatmaps_fts'").Scan(&count); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		var state string
+
+		if err := dbFile.QueryRow("SELECT value FROM info WHERE key = 'search_index'").Scan(&state); err != nil || state != "stale" {
+			return nil
+		}
+
+		log.Println("DatabaseManager: Beatmaps were changed by a build without FTS5, rebuilding search index...")
+
+		return RebuildSearchIndex()
+	}
+
+	log.Println("DatabaseManager: Building search index...")
+
+	_, err := dbFile.Exec(getSearchIndexStmts() + clearSearchStaleStmt)
+
+	return err
+}
+
+// RebuildSearchIndex rebuilds the full-text index from beatmaps table
+func RebuildSearchIndex() error {
+	if !searchAvailable {
+		return fmt.Errorf("full-text search is not available")
+	}
+
+	_, err := dbFile.Exec(searchRebuildStmt + clearSearchStaleStmt)
+
+	return err
+}
+
+type dbExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// searchIndexUpdater adds and removes rows of beatmaps matching the condition to/from the search index.
+// Removal has to be done before the rows are deleted from beatmaps and addition after they are inserted.
+// Without FTS5 it only marks the index as stale.
+type searchIndexUpdater struct {
+	insert *sql.Stmt
+	remove *sql.Stmt
+}
+
+func newSearchIndexUpdater(db dbExecutor, condition string) (*searchIndexUpdater, error) {
+	updater := &searchIndexUpdater{}
+
+	if !searchAvailable {
+		_, err := db.Exec(markSearchStaleStmt)
+
+		return updater, err
+	}
+
+	var err error
+
+	updater.insert, err = db.Prepare(fmt.Sprintf("INSERT INTO beatmaps_fts (rowid, %[1]s) SELECT rowid, %[1]s FROM beatmaps WHERE %[2]s", searchColumns, condition))
+	if err != nil {
+		return nil, err
+	}
+
+	updater.remove, err = db.Prepare(fmt.Sprintf("INSERT INTO beatmaps_fts (beatmaps_fts, rowid, %[1]s) SELECT 'delete', rowid, %[1]s FROM beatmaps WHERE %[2]s", searchColumns, condition))
+	if err != nil {
+		updater.insert.Close()
+		return nil, err
+	}
+
+	return updater, nil
+}
+
+func (updater *searchIndexUpdater) add(args ...any) error {
+	if updater.insert == nil {
+		return nil
+	}
+
+	_, err := updater.insert.Exec(args...)
+
+	return err
+}
+
+func (updater *searchIndexUpdater) delete(args ...any) error {
+	if updater.remove == nil {
+		return nil
+	}
+
+	_, err := updater.remove.Exec(args...)
+
+	return err
+}
+
+func (updater *searchIndexUpdater) close() {
+	if updater.insert != nil {
+		updater.insert.Close()
+		updater.remove.Close()
+	}
+}
+
+// SearchAvailable returns true if database is open and full-text search is supported
+func SearchAvailable() bool {
+	return dbFile != nil && searchAvailable
+}
+
+// ErrNoSearchWords is returned by SearchBeatmaps when the text has no words to search for (e.g. only punctuation),
+// callers should fall back to simple matching
+var ErrNoSearchWords = errors.New("search text has no words")
+
+// SearchResult is a beatmap matched by SearchBeatmaps
+type SearchResult struct {
+	Dir  string
+	File string
+	Rank float64
+}
+
+// SearchBeatmaps searches title, artist, their unicode variants, creator, source, tags and difficulty name.
+// Every word has to match the beginning of a word in one of these fields. Results are sorted by relevance, best first.
+func SearchBeatmaps(text string, limit int) ([]SearchResult, error) {
+	if !SearchAvailable() {
+		return nil, fmt.Errorf("full-text search is not available")
+	}
+
+	match := buildMatchQuery(text)
+	if match == "" {
+		return nil, ErrNoSearchWords
+	}
+
+	query := fmt.Sprintf("SELECT b.dir, b.file, %s AS rank FROM beatmaps_fts JOIN beatmaps b ON b.rowid = beatmaps_fts.rowid WHERE beatmaps_fts MATCH ? ORDER BY rank", searchRankStmt)
+
+	args := []any{match}
+
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	res, err := dbFile.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	var results []SearchResult
+
+	for res.Next() {
+		var r SearchResult
+
+		if err = res.Scan(&r.Dir, &r.File, &r.Rank); err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+
+	return results, res.Err()
+}
+
+// FilterBySearch returns beatmaps matching the text, sorted by relevance
+func FilterBySearch(beatmaps []*beatmap.BeatMap, text string) ([]*beatmap.BeatMap, error) {
+	results, err := SearchBeatmaps(text, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	byLocation := make(map[mapLocation]*beatmap.BeatMap, len(beatmaps))
+
+	for _, b := range beatmaps {
+		byLocation[mapLocation{dir: b.Dir, file: b.File}] = b
+	}
+
+	found := make([]*beatmap.BeatMap, 0, len(results))
+
+	for _, r := range results {
+		if b, ok := byLocation[mapLocation{dir: r.Dir, file: r.File}]; ok {
+			found = append(found, b)
+		}
+	}
+
+	return found, nil
+}
+
+// buildMatchQuery converts user input to FTS5 query where every word is a quoted prefix, e.g. `brain pow` -> `"brain"* "pow"*`
+func buildMatchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+
+	terms := make([]string, 0, len(words))
+
+	for _, w := range words {
+		terms = append(terms, "\""+strings.ReplaceAll(w, "\"", "\"\"")+"\"*")
+	}
+
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,560 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/olekukonko/tablewriter"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/query"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/bass"
+	"github.com/wieku/danser-go/framework/platform"
+	"log"
+	"os"
+	"strings"
+)
+
+var allModes = []int64{0, 1, 2, 3}
+
+var modeNames = []string{"osu!", "osu!taiko", "osu!catch", "osu!mania"}
+
+type dbCommand struct {
+	name        string
+	args        string
+	description string
+}
+
+var dbCommands = []dbCommand{
+	{"import", "", "Import new and changed beatmaps from Songs directory"},
+	{"reindex", "", "Parse all .osu files again, keeps play counts, local offsets and star rating of unchanged maps"},
+	{"recalc-sr", "", "Recalculate star rating of all osu!standard beatmaps"},
+	{"ls", "[query]", "List beatmaps matching the query, see -query for syntax. Lists all beatmaps if query is empty"},
+	{"search", "<text>", "Search beatmaps by title, artist, creator, source, tags and difficulty name, best matches first"},
+	{"stats", "", "Show beatmap counts by game mode and beatmaps with missing files"},
+	{"health", "", "Report beatmaps with missing or undecodable assets and .osu files that failed to import"},
+	{"duplicates", "", "Find directories containing the same beatmaps or beatmap sets, optionally move redundant ones to quarantine"},
+	{"roundtrip", "[query]", "Write osu!standard beatmaps matching the query with danser's .osu writer, parse them again and report differences"},
+	{"vacuum", "", "Compact the database file"},
+}
+
+// runDatabaseCommand handles "danser-cli db <command>" used to manage the beatmap library without the launcher
+func runDatabaseCommand(args []string) {
+	if len(args) == 0 || !isDBCommand(args[0]) {
+		if len(args) > 0 && args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+			log.Println(fmt.Sprintf("Unknown database command: \"%s\"", args[0]))
+		}
+
+		printDBUsage()
+
+		return
+	}
+
+	command := args[0]
+
+	flags := flag.NewFlagSet("db "+command, flag.ExitOnError)
+
+	settingsVersion := flags.String("settings", "", "Specify settings version, -settings=b/abc means that settings/b/abc.json will be loaded")
+
+	var noDbCheck, jsonOut, recheck, brokenOnly, quarantine *bool
+	var version, quarantineDir *string
+	var limit *int
+
+	switch command {
+	case "import":
+		noDbCheck = flags.Bool("nodbcheck", false, "Import only new directories, don't check existing ones for changes")
+	case "recalc-sr":
+		names := make([]string, 0, len(performance.Versions))
+		for _, v := range performance.Versions {
+			names = append(names, v.Name)
+		}
+
+		version = flags.String("version", names[len(names)-1], "pp version used to calculate star rating, one of: "+strings.Join(names, ", ")+". Versions older than the one used by danser are rejected, because they would be recalculated when launcher or -query need star rating")
+	case "ls", "search", "stats", "health", "duplicates":
+		jsonOut = flags.Bool("json", false, "Print results to stdout as JSON, logs are printed to stderr")
+
+		switch command {
+		case "search":
+			limit = flags.Int("limit", 50, "Maximum number of results, 0 means no limit")
+		case "health":
+			recheck = flags.Bool("recheck", false, "Check assets of all beatmaps again, e.g. the ones imported before asset checks were introduced")
+			brokenOnly = flags.Bool("broken", false, "Show only beatmaps with missing or undecodable audio")
+		case "duplicates":
+			quarantine = flags.Bool("quarantine", false, "Move redundant directories to the quarantine directory and remove them from the database")
+			quarantineDir = flags.String("to", database.DefaultQuarantineDir(), "Quarantine directory")
+		}
+	}
+
+	flags.Parse(args[1:])
+
+	if jsonOut != nil && *jsonOut {
+		platform.SetConsoleOutput(os.Stderr)
+	}
+
+	var calcVersion *performance.Version
+
+	if version != nil {
+		for _, v := range performance.Versions {
+			if v.Name == *version {
+				calcVersion = &v
+				break
+			}
+		}
+
+		if calcVersion == nil {
+			panic(fmt.Sprintf("flag -version: unknown pp version \"%s\"", *version))
+		}
+
+		if calcVersion.NewDifficultyCalculator().GetVersion() < database.StarRatingVersion() {
+			panic(fmt.Sprintf("flag -version: pp version \"%s\" is older than the one used by danser, star rating would be recalculated on next use", *version))
+		}
+	}
+
+	var beatmapQuery *query.Query
+
+	if (command == "ls" || command == "roundtrip") && flags.NArg() > 0 {
+		var err error
+		if beatmapQuery, err = query.Parse(strings.Join(flags.Args(), " ")); err != nil {
+			panic(fmt.Sprintf("Failed to parse query: %s", err))
+		}
+	} else if command == "search" && flags.NArg() == 0 {
+		panic("search requires a text to search for")
+	}
+
+	if *settingsVersion == "credentials" || *settingsVersion == "launcher" {
+		panic(fmt.Sprintf("flag -settings: name \"%s\" is forbidden", *settingsVersion))
+	}
+
+	settings.LoadSettings(*settingsVersion)
+
+	// Migrations and imports check beatmap assets, BASS has to be ready before the database is opened to decode audio files
+	initAudio()
+
+	if err := database.Init(); err != nil {
+		panic(fmt.Sprintf("Failed to initialize database: %s", err))
+	}
+
+	defer database.Close()
+
+	switch command {
+	case "import":
+		database.ImportBeatmaps(*noDbCheck, logImportProgress())
+	case "reindex":
+		database.Reindex(logImportProgress())
+	case "recalc-sr":
+		log.Println("Recalculating star rating using", calcVersion.Name, "pp version...")
+
+		database.RecalculateStarRating(database.GetBeatmaps(0), calcVersion.NewDifficultyCalculator(), logStarRatingProgress())
+	case "ls":
+		beatmaps := database.GetBeatmaps(allModes...)
+
+		if beatmapQuery != nil {
+			beatmaps = filterByQuery(beatmaps, beatmapQuery)
+		}
+
+		printDBBeatmaps(beatmaps, *jsonOut)
+	case "search":
+		beatmaps := searchBeatmaps(strings.Join(flags.Args(), " "))
+
+		if *limit > 0 && len(beatmaps) > *limit {
+			beatmaps = beatmaps[:*limit]
+		}
+
+		printDBBeatmaps(beatmaps, *jsonOut)
+	case "stats":
+		printDBStats(database.GetStats(), *jsonOut)
+	case "health":
+		beatmaps := database.GetBeatmaps(allModes...)
+
+		if *recheck {
+			log.Println("Checking beatmap assets...")
+
+			database.RecheckAssets(beatmaps, logProgress("Checking assets"))
+		}
+
+		printHealthReport(beatmaps, database.GetImportErrors(), *brokenOnly, *jsonOut)
+	case "duplicates":
+		groups := database.FindDuplicates()
+
+		printDuplicates(groups, *jsonOut)
+
+		if *quarantine && len(groups) > 0 {
+			moved, freed, err := database.QuarantineDuplicates(groups, *quarantineDir)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to move duplicates: %s", err))
+			}
+
+			log.Println(fmt.Sprintf("Moved %d directories (%s) to \"%s\"", moved, humanize.IBytes(uint64(freed)), *quarantineDir))
+		}
+	case "roundtrip":
+		beatmaps := database.GetBeatmaps(0)
+
+		if beatmapQuery != nil {
+			beatmaps = filterByQuery(beatmaps, beatmapQuery)
+		}
+
+		checkRoundTrip(beatmaps)
+	case "vacuum":
+		before, after, err := database.Vacuum()
+		if err != nil {
+			panic(fmt.Sprintf("Failed to vacuum database: %s", err))
+		}
+
+		log.Println(fmt.Sprintf("Database compacted: %s -> %s", humanize.IBytes(uint64(before)), humanize.IBytes(uint64(after))))
+	}
+}
+
+// checkRoundTrip writes every beatmap with beatmap.WriteBeatMap, parses it again and logs beatmaps that changed
+func checkRoundTrip(beatmaps []*beatmap.BeatMap) {
+	// Spinners are written only if they were loaded
+	settings.Objects.LoadSpinners = true
+
+	progress := logProgress("Checking")
+
+	var rows [][]string
+
+	for i, b := range beatmaps {
+		diffs, err := roundTripBeatmap(b)
+
+		if err != nil {
+			rows = append(rows, []string{b.Dir + "/" + b.File, err.Error()})
+		} else if len(diffs) > 0 {
+			rows = append(rows, []string{b.Dir + "/" + b.File, fmt.Sprintf("%s (%d differences)", diffs[0], len(diffs))})
+		}
+
+		progress(i+1, len(beatmaps))
+	}
+
+	log.Println(fmt.Sprintf("%d/%d beatmaps are the same after writing", len(beatmaps)-len(rows), len(beatmaps)))
+
+	if len(rows) > 0 {
+		logTable([]string{"File", "Difference"}, rows)
+	}
+}
+
+func roundTripBeatmap(b *beatmap.BeatMap) (diffs []string, err error) {
+	defer func() {
+		b.Clear()
+		b.Pauses = nil
+
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse: %v", r)
+		}
+	}()
+
+	beatmap.ParseTimingPointsAndPauses(b)
+	beatmap.ParseObjects(b, true, false)
+
+	return beatmap.CheckRoundTrip(b)
+}
+
+func isDBCommand(name string) bool {
+	for _, c := range dbCommands {
+		if c.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func printDBUsage() {
+	log.Println("Usage: danser-cli db <command> [flags] [arguments]")
+	log.Println("Commands:")
+
+	for _, c := range dbCommands {
+		log.Println(fmt.Sprintf("  %-10s %-8s %s", c.name, c.args, c.description))
+	}
+
+	log.Println("Run \"danser-cli db <command> -h\" to see command's flags")
+}
+
+// searchBeatmaps uses full-text index if it's available, otherwise words are matched like in -query
+func searchBeatmaps(text string) []*beatmap.BeatMap {
+	beatmaps := database.GetBeatmaps(allModes...)
+
+	if database.SearchAvailable() {
+		found, err := database.FilterBySearch(beatmaps, text)
+		if err == nil {
+			return found
+		}
+
+		if !errors.Is(err, database.ErrNoSearchWords) {
+			log.Println("Full-text search failed, falling back to simple matching:", err)
+		}
+	} else {
+		log.Println("Full-text search is not available, results won't be sorted by relevance")
+	}
+
+	q := query.ParseWords(text)
+
+	return q.Filter(beatmaps)
+}
+
+// initAudio initializes BASS without an output device, so audio files can be decoded during import
+func initAudio() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Println("Failed to initialize BASS, audio files won't be decoded:", err)
+		}
+	}()
+
+	bass.Init(true)
+}
+
+// logProgress returns a listener logging progress every 10%
+func logProgress(title string) func(processed, target int) {
+	lastDecile := -1
+
+	return func(processed, target int) {
+		if decile := processed * 10 / max(target, 1); decile != lastDecile {
+			lastDecile = decile
+
+			log.Println(fmt.Sprintf("%s: %d/%d (%d%%)", title, processed, target, processed*100/max(target, 1)))
+		}
+	}
+}
+
+func logImportProgress() database.ImportListener {
+	listener := logProgress("Importing")
+
+	return func(stage database.ImportStage, progress, target int) {
+		if stage == database.Import && target > 0 {
+			listener(progress, target)
+		}
+	}
+}
+
+func logStarRatingProgress() func(processed, target int, message string) {
+	listener := logProgress("Calculating star rating")
+
+	return func(processed, target int, message string) {
+		if message != "" {
+			log.Println(message)
+		}
+
+		listener(processed, target)
+	}
+}
+
+func printDBBeatmaps(beatmaps []*beatmap.BeatMap, jsonOut bool) {
+	if !jsonOut {
+		log.Println(fmt.Sprintf("Found %d beatmaps", len(beatmaps)))
+
+		printBeatmapList(beatmaps)
+
+		return
+	}
+
+	listed := make([]listedBeatmap, 0, len(beatmaps))
+
+	for _, b := range beatmaps {
+		listed = append(listed, newListedBeatmap(b))
+	}
+
+	printJSON(listed)
+}
+
+func printDBStats(stats *database.LibraryStats, jsonOut bool) {
+	if jsonOut {
+		printJSON(stats)
+		return
+	}
+
+	rows := [][]string{
+		{"Beatmaps", fmt.Sprintf("%d", stats.Beatmaps)},
+		{"Beatmap sets", fmt.Sprintf("%d", stats.Sets)},
+	}
+
+	for mode, name := range modeNames {
+		rows = append(rows, []string{name, fmt.Sprintf("%d", stats.ByMode[int64(mode)])})
+	}
+
+	rows = append(rows,
+		[]string{"Without star rating", fmt.Sprintf("%d", stats.NoStars)},
+		[]string{"Outdated star rating", fmt.Sprintf("%d", stats.OldStars)},
+		[]string{"Missing .osu files", fmt.Sprintf("%d", len(stats.MissingBeatmaps))},
+		[]string{"Missing audio", fmt.Sprintf("%d", len(stats.MissingAudio))},
+		[]string{"Missing backgrounds", fmt.Sprintf("%d", len(stats.MissingBackgrounds))},
+		[]string{"Full-text search", fmt.Sprintf("%t", stats.SearchIndex)},
+	)
+
+	logTable([]string{"Statistic", "Value"}, rows)
+
+	logMissing := func(title string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+
+		log.Println(title)
+
+		for _, p := range paths {
+			log.Println("  ", p)
+		}
+	}
+
+	logMissing("Beatmaps with missing .osu file (run \"danser-cli db import\" to remove them):", stats.MissingBeatmaps)
+	logMissing("Beatmaps with missing audio:", stats.MissingAudio)
+	logMissing("Beatmaps with missing background:", stats.MissingBackgrounds)
+}
+
+func printJSON(v any) {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(data))
+}
+
+type assetHealth struct {
+	Path         string   `json:"path"`
+	MD5          string   `json:"md5"`
+	Broken       bool     `json:"broken"`
+	AudioChecked bool     `json:"audioChecked"`
+	Problems     []string `json:"problems"`
+}
+
+type healthReport struct {
+	Checked        int                    `json:"checked"`
+	Unchecked      int                    `json:"unchecked"`
+	AudioUnchecked int                    `json:"audioUnchecked"`
+	Beatmaps       []assetHealth          `json:"beatmaps"`
+	ImportErrors   []database.ImportError `json:"importErrors"`
+}
+
+func printHealthReport(beatmaps []*beatmap.BeatMap, importErrors []database.ImportError, brokenOnly, jsonOut bool) {
+	report := healthReport{
+		Beatmaps:     make([]assetHealth, 0),
+		ImportErrors: importErrors,
+	}
+
+	for _, b := range beatmaps {
+		if b.Assets == beatmap.AssetsUnchecked {
+			report.Unchecked++
+			continue
+		}
+
+		report.Checked++
+
+		// Maps imported without BASS (e.g. by the main program) may have undecodable audio, other files are still checked
+		audioChecked := b.Assets.Verified()
+		if !audioChecked {
+			report.AudioUnchecked++
+		}
+
+		if problems := (b.Assets &^ beatmap.AudioUnverified).Problems(); len(problems) > 0 && (!brokenOnly || b.Assets.Broken()) {
+			report.Beatmaps = append(report.Beatmaps, assetHealth{
+				Path:         b.Dir + "/" + b.File,
+				MD5:          b.MD5,
+				Broken:       b.Assets.Broken(),
+				AudioChecked: audioChecked,
+				Problems:     problems,
+			})
+		}
+	}
+
+	if jsonOut {
+		printJSON(report)
+		return
+	}
+
+	log.Println(fmt.Sprintf("Checked %d beatmaps, %d with problems", report.Checked, len(report.Beatmaps)))
+
+	if report.Unchecked > 0 || report.AudioUnchecked > 0 {
+		log.Println(fmt.Sprintf("%d beatmaps weren't checked yet and audio of %d beatmaps wasn't decoded, run \"danser-cli db health -recheck\" to check them", report.Unchecked, report.AudioUnchecked))
+	}
+
+	if len(report.Beatmaps) > 0 {
+		rows := make([][]string, 0, len(report.Beatmaps))
+
+		for _, h := range report.Beatmaps {
+			broken := "unchecked"
+			if h.AudioChecked {
+				broken = fmt.Sprintf("%t", h.Broken)
+			}
+
+			rows = append(rows, []string{h.Path, broken, strings.Join(h.Problems, ", ")})
+		}
+
+		logTable([]string{"Beatmap", "Broken", "Problems"}, rows)
+	}
+
+	if len(importErrors) > 0 {
+		log.Println(fmt.Sprintf("%d files failed to import:", len(importErrors)))
+
+		rows := make([][]string, 0, len(importErrors))
+
+		for _, e := range importErrors {
+			rows = append(rows, []string{e.Dir + "/" + e.File, e.Error})
+		}
+
+		logTable([]string{"File", "Error"}, rows)
+	}
+}
+
+func logTable(header []string, rows [][]string) {
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.Render()
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+}
+
+func printDuplicates(groups []*database.DuplicateGroup, jsonOut bool) {
+	if jsonOut {
+		printJSON(groups)
+		return
+	}
+
+	if len(groups) == 0 {
+		log.Println("No duplicates found")
+		return
+	}
+
+	var redundant int64
+
+	rows := make([][]string, 0)
+
+	for _, g := range groups {
+		redundant += g.RedundantSize()
+
+		reasons := make([]string, 0, 2)
+
+		if g.SameMD5 {
+			reasons = append(reasons, "MD5")
+		}
+
+		if g.SameSetID {
+			reasons = append(reasons, "set ID")
+		}
+
+		for i, d := range g.Dirs {
+			title, reason := "", ""
+			if i == 0 {
+				title, reason = g.Title, strings.Join(reasons, ", ")
+			}
+
+			action := "keep"
+			if d.Redundant {
+				action = "move"
+			} else if !d.Keep {
+				action = "report" // has maps the kept directory doesn't
+			}
+
+			rows = append(rows, []string{title, reason, d.Dir, fmt.Sprintf("%d", d.Beatmaps), humanize.IBytes(uint64(d.Size)), action})
+		}
+	}
+
+	logTable([]string{"Beatmap set", "Same", "Directory", "Beatmaps", "Size", "Action"}, rows)
+
+	log.Println(fmt.Sprintf("Found %d duplicate groups, %s can be freed", len(groups), humanize.IBytes(uint64(redundant))))
+}
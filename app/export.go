@@ -0,0 +1,19 @@
+package app
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/dance"
+	"log"
+)
+
+func runExport(beatMap *beatmap.BeatMap) {
+	log.Println("Exporting cursordance...")
+
+	paths := dance.ExportDance(beatMap)
+
+	if len(paths) == 0 {
+		panic("Failed to export cursordance")
+	}
+
+	log.Println("Exported", len(paths), "replay(s)")
+}
@@ -85,9 +85,11 @@ type Cursor struct {
 
 	Position vector.Vector2f
 
-	Name      string
-	ScoreID   int64
-	ScoreTime time.Time
+	Name       string
+	ScoreID    int64
+	ScoreTime  time.Time
+	LifeBar    []vector.Vector2d // Life bar graph recorded in the replay, X is time, Y is hp in 0-1 range
+	ReplayPath string
 
 	lastSetting bool
 
@@ -106,6 +108,10 @@ type Cursor struct {
 }
 
 func NewCursor() *Cursor {
+	if settings.HEADLESS {
+		return newHeadlessCursor()
+	}
+
 	if cursorFbo == nil {
 		initCursor()
 	}
@@ -131,6 +137,18 @@ func NewCursor() *Cursor {
 	return cursor
 }
 
+// newHeadlessCursor creates a cursor that only tracks input state, it doesn't touch OpenGL or skin resources
+func newHeadlessCursor() *Cursor {
+	cursor := &Cursor{Position: vector.NewVec2f(256, -500)}
+	cursor.scale = animation.NewGlider(1.0)
+	cursor.renderer = headlessRenderer{}
+
+	cursor.smokeContainer = sprite.NewManager()
+	cursor.rippleContainer = sprite.NewManager()
+
+	return cursor
+}
+
 func (cursor *Cursor) SetPos(pt vector.Vector2f) {
 	cursor.RawPosition = pt
 	tmp := pt
@@ -185,6 +203,10 @@ func (cursor *Cursor) SetScreenPos(pt vector.Vector2f) {
 }
 
 func (cursor *Cursor) Update(delta float64) {
+	if settings.HEADLESS {
+		return
+	}
+
 	delta = math.Abs(delta)
 	cursor.time += delta
 
@@ -0,0 +1,18 @@
+package graphics
+
+import (
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+// headlessRenderer is used when danser runs without a window, it discards everything
+type headlessRenderer struct{}
+
+func (headlessRenderer) SetPosition(_ vector.Vector2f) {}
+
+func (headlessRenderer) Update(_ float64) {}
+
+func (headlessRenderer) UpdateRenderer() {}
+
+func (headlessRenderer) DrawM(_, _ float64, _ *batch.QuadBatch, _ color2.Color, _ color2.Color) {}
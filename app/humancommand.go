@@ -0,0 +1,150 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/dance/human"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"strings"
+)
+
+// runHumanModelCommand handles "danser-cli human-model <replays directory>" which builds a model used by the human mover
+func runHumanModelCommand(args []string) {
+	flags := flag.NewFlagSet("human-model", flag.ExitOnError)
+
+	settingsVersion := flags.String("settings", "", "Specify settings version, -settings=b/abc means that settings/b/abc.json will be loaded")
+	out := flags.String("out", human.DefaultModelPath(), "Path the model will be saved to")
+	player := flags.String("player", "", "Use only replays of this player")
+
+	flags.Usage = func() {
+		log.Println("Usage: danser-cli human-model [flags] <replays directory>")
+		log.Println("Builds a model of cursor movement and tapping used by the human mover from .osr files. Replayed beatmaps have to be in the database")
+
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return
+	}
+
+	if *settingsVersion == "credentials" || *settingsVersion == "launcher" {
+		panic(fmt.Sprintf("flag -settings: name \"%s\" is forbidden", *settingsVersion))
+	}
+
+	replays, err := files.SearchFiles(flags.Arg(0), "*.osr", -1)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to search for replays: %s", err))
+	}
+
+	if len(replays) == 0 {
+		panic(fmt.Sprintf("No replays found in \"%s\"", flags.Arg(0)))
+	}
+
+	settings.LoadSettings(*settingsVersion)
+
+	if err = database.Init(); err != nil {
+		panic(fmt.Sprintf("Failed to initialize database: %s", err))
+	}
+
+	defer database.Close()
+
+	beatmaps := make(map[string]*beatmap.BeatMap)
+
+	for _, b := range database.GetBeatmaps(0) {
+		beatmaps[strings.ToLower(b.MD5)] = b
+	}
+
+	trainer := human.NewTrainer()
+
+	skipped := 0
+
+	for i, path := range replays {
+		if err = addHumanReplay(trainer, beatmaps, path, *player); err != nil {
+			log.Println(fmt.Sprintf("Skipping \"%s\": %s", path, err))
+			skipped++
+		}
+
+		if (i+1)%50 == 0 {
+			log.Println(fmt.Sprintf("Processed %d/%d replays", i+1, len(replays)))
+		}
+	}
+
+	model := trainer.Model()
+
+	if model.Replays == 0 {
+		panic("None of the replays could be used")
+	}
+
+	if err = model.Save(*out); err != nil {
+		panic(fmt.Sprintf("Failed to save the model: %s", err))
+	}
+
+	log.Println(fmt.Sprintf("Built the model from %d replays (%d skipped) and %d movements:", model.Replays, skipped, model.Movements))
+
+	stat := func(name string, s human.Stat, format string) []string {
+		return []string{name, fmt.Sprintf(format, s.Mean), fmt.Sprintf(format, s.StdDev)}
+	}
+
+	logTable([]string{"Measure", "Mean", "Std dev"}, [][]string{
+		stat("Hit offset (ms)", model.HitOffset, "%.2f"),
+		stat("Aim error (radius)", model.AimError, "%.3f"),
+		stat("Press duration (ms)", model.PressDuration, "%.2f"),
+		stat("Curvature", model.Curvature, "%.3f"),
+		stat("Overshoot", model.Overshoot, "%.3f"),
+		stat("Overshoot time (ms)", model.OvershootTime, "%.2f"),
+		{"Single tap above (ms)", fmt.Sprintf("%.0f", model.SingleTapThreshold), ""},
+	})
+
+	log.Println("Model saved to:", *out)
+}
+
+func addHumanReplay(trainer *human.Trainer, beatmaps map[string]*beatmap.BeatMap, path, player string) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		return err
+	}
+
+	if player != "" && !strings.EqualFold(replay.Username, player) {
+		return fmt.Errorf("played by %s", replay.Username)
+	}
+
+	bMap, ok := beatmaps[strings.ToLower(replay.BeatmapMD5)]
+	if !ok {
+		return fmt.Errorf("beatmap %s is not in the database", replay.BeatmapMD5)
+	}
+
+	defer func() {
+		bMap.Clear()
+
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to load the beatmap: %v", r)
+		}
+	}()
+
+	mods := difficulty.Modifier(replay.Mods)
+	if replay.OsuVersion >= 30000000 {
+		mods |= difficulty.Lazer
+	}
+
+	bMap.Diff.SetMods(mods)
+
+	beatmap.ParseTimingPointsAndPauses(bMap)
+	beatmap.ParseObjects(bMap, true, false)
+
+	return trainer.AddReplay(bMap, replay)
+}
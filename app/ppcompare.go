@@ -0,0 +1,129 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ppComparison struct {
+	Version         string  `json:"version"`
+	Stars           float64 `json:"stars"`
+	StarsAim        float64 `json:"stars_aim"`
+	StarsSpeed      float64 `json:"stars_speed"`
+	StarsFlashlight float64 `json:"stars_flashlight"`
+	PP              float64 `json:"pp"`
+	PPAim           float64 `json:"pp_aim"`
+	PPSpeed         float64 `json:"pp_speed"`
+	PPAcc           float64 `json:"pp_acc"`
+	PPFlashlight    float64 `json:"pp_flashlight"`
+}
+
+func runPPCompare(beatMap *beatmap.BeatMap) {
+	score := api.PerfScore{CountGreat: -1, MaxCombo: -1, Accuracy: 1, SliderEnd: -1}
+	scoreName := "SS"
+
+	if settings.REPLAY != "" {
+		data, err := os.ReadFile(settings.REPLAY)
+		if err != nil {
+			panic(err)
+		}
+
+		replay, err := rplpa.ParseReplay(data)
+		if err != nil {
+			panic(err)
+		}
+
+		score = getReplayPerfScore(replay)
+		scoreName = fmt.Sprintf("%s's play (%.2f%%, %dx, %d miss)", replay.Username, score.Accuracy*100, score.MaxCombo, score.CountMiss)
+	}
+
+	log.Println(fmt.Sprintf("Comparing pp versions for %s - %s [%s] +%s, %s:", beatMap.Artist, beatMap.Name, beatMap.Difficulty, beatMap.Diff.GetModString(), scoreName))
+
+	results := performance.CompareVersions(beatMap.HitObjects, beatMap.Diff, score)
+
+	comparisons := make([]ppComparison, 0, len(results))
+
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader([]string{"Version", "Stars", "Aim", "Speed", "FL", "PP", "Aim PP", "Speed PP", "Acc PP", "FL PP"})
+
+	for _, r := range results {
+		comparisons = append(comparisons, ppComparison{
+			Version:         r.Version,
+			Stars:           r.Stars.Total,
+			StarsAim:        r.Stars.Aim,
+			StarsSpeed:      r.Stars.Speed,
+			StarsFlashlight: r.Stars.Flashlight,
+			PP:              r.PP.Total,
+			PPAim:           r.PP.Aim,
+			PPSpeed:         r.PP.Speed,
+			PPAcc:           r.PP.Acc,
+			PPFlashlight:    r.PP.Flashlight,
+		})
+
+		table.Append([]string{
+			r.Version,
+			fmt.Sprintf("%.2f", r.Stars.Total),
+			fmt.Sprintf("%.2f", r.Stars.Aim),
+			fmt.Sprintf("%.2f", r.Stars.Speed),
+			fmt.Sprintf("%.2f", r.Stars.Flashlight),
+			fmt.Sprintf("%.2f", r.PP.Total),
+			fmt.Sprintf("%.2f", r.PP.Aim),
+			fmt.Sprintf("%.2f", r.PP.Speed),
+			fmt.Sprintf("%.2f", r.PP.Acc),
+			fmt.Sprintf("%.2f", r.PP.Flashlight),
+		})
+	}
+
+	table.Render()
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+
+	if output == "" {
+		return
+	}
+
+	path := strings.TrimSuffix(output, filepath.Ext(output)) + ".json"
+
+	data, err := json.MarshalIndent(comparisons, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		panic("Failed to save the comparison: " + err.Error())
+	}
+
+	log.Println("Comparison saved to:", path)
+}
+
+// getReplayPerfScore builds PerfScore from hit counts stored in replay's header
+func getReplayPerfScore(replay *rplpa.Replay) api.PerfScore {
+	score := api.PerfScore{
+		MaxCombo:   int(replay.MaxCombo),
+		CountGreat: int(replay.Count300),
+		CountOk:    int(replay.Count100),
+		CountMeh:   int(replay.Count50),
+		CountMiss:  int(replay.CountMiss),
+		SliderEnd:  -1,
+		Accuracy:   1,
+	}
+
+	if total := score.CountGreat + score.CountOk + score.CountMeh + score.CountMiss; total > 0 {
+		score.Accuracy = float64(score.CountGreat*300+score.CountOk*100+score.CountMeh*50) / float64(total*300)
+	}
+
+	return score
+}
@@ -0,0 +1,161 @@
+package mania
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"math"
+)
+
+type HitResult int
+
+const (
+	Ignore = HitResult(iota)
+	Miss
+	Hit50
+	Hit100
+	Hit200
+	Hit300
+	HitMax
+)
+
+func (r HitResult) String() string {
+	switch r {
+	case Miss:
+		return "Miss"
+	case Hit50:
+		return "50"
+	case Hit100:
+		return "100"
+	case Hit200:
+		return "200"
+	case Hit300:
+		return "300"
+	case HitMax:
+		return "MAX"
+	default:
+		return "Ignore"
+	}
+}
+
+// value returns the score value of the judgement in osu!stable's ScoreV1
+func (r HitResult) value() float64 {
+	switch r {
+	case HitMax:
+		return 320
+	case Hit300:
+		return 300
+	case Hit200:
+		return 200
+	case Hit100:
+		return 100
+	case Hit50:
+		return 50
+	default:
+		return 0
+	}
+}
+
+// bonus returns bonus value and bonus change of the judgement in osu!stable's ScoreV1
+func (r HitResult) bonus() (value, change float64) {
+	switch r {
+	case HitMax:
+		return 32, 2
+	case Hit300:
+		return 32, 1
+	case Hit200:
+		return 16, -8
+	case Hit100:
+		return 8, -24
+	case Hit50:
+		return 4, -44
+	default:
+		return 0, -100
+	}
+}
+
+// HitWindows are distances from note's time in ms, a press outside the Miss window is ignored
+type HitWindows struct {
+	Max  float64
+	W300 float64
+	W200 float64
+	W100 float64
+	W50  float64
+	Miss float64
+}
+
+// NewHitWindows calculates osu!stable's hit windows for given difficulty
+func NewHitWindows(diff *difficulty.Difficulty) HitWindows {
+	od := diff.GetOD()
+
+	windows := HitWindows{
+		Max:  16,
+		W300: 64 - 3*od,
+		W200: 97 - 3*od,
+		W100: 127 - 3*od,
+		W50:  151 - 3*od,
+		Miss: 188 - 3*od,
+	}
+
+	mult := 1.0
+
+	if diff.CheckModActive(difficulty.HardRock) {
+		mult = 1 / 1.4
+	} else if diff.CheckModActive(difficulty.Easy) {
+		mult = 1.4
+	}
+
+	return windows.scale(mult)
+}
+
+func (windows HitWindows) scale(mult float64) HitWindows {
+	return HitWindows{
+		Max:  windows.Max * mult,
+		W300: windows.W300 * mult,
+		W200: windows.W200 * mult,
+		W100: windows.W100 * mult,
+		W50:  windows.W50 * mult,
+		Miss: windows.Miss * mult,
+	}
+}
+
+// ResultFor returns judgement for given hit error
+func (windows HitWindows) ResultFor(hitError float64) HitResult {
+	if hitError < 0 {
+		hitError = -hitError
+	}
+
+	switch {
+	case hitError <= windows.Max:
+		return HitMax
+	case hitError <= windows.W300:
+		return Hit300
+	case hitError <= windows.W200:
+		return Hit200
+	case hitError <= windows.W100:
+		return Hit100
+	case hitError <= windows.W50:
+		return Hit50
+	case hitError <= windows.Miss:
+		return Miss
+	default:
+		return Ignore
+	}
+}
+
+// HoldResultFor returns osu!stable's judgement of a hold note from hit errors of its head and release
+func (windows HitWindows) HoldResultFor(headError, tailError float64) HitResult {
+	head := math.Abs(headError)
+	sum := head + math.Abs(tailError)
+
+	switch {
+	case head <= windows.Max*1.2 && sum <= windows.Max*2.4:
+		return HitMax
+	case head <= windows.W300*1.1 && sum <= windows.W300*2.2:
+		return Hit300
+	case head <= windows.W200 && sum <= windows.W200*2:
+		return Hit200
+	case head <= windows.W100 && sum <= windows.W100*2:
+		return Hit100
+	default:
+		return Hit50
+	}
+}
@@ -0,0 +1,58 @@
+package mania
+
+import (
+	"cmp"
+	"github.com/wieku/rplpa"
+	"slices"
+)
+
+const seedFrameTime = -12345
+
+type keyEvent struct {
+	time    float64
+	column  int
+	pressed bool
+}
+
+// buildKeyEvents converts replay frames to column press and release events.
+// In osu!mania replays X coordinate of the frame holds the bitmask of pressed columns.
+func buildKeyEvents(frames []*rplpa.ReplayData, keys int) (events []keyEvent) {
+	time := 0.0
+	lastMask := 0
+
+	for _, frame := range frames {
+		if frame.Time == seedFrameTime {
+			continue
+		}
+
+		time += frame.Time
+
+		mask := int(frame.MouseX)
+
+		if mask == lastMask {
+			continue
+		}
+
+		for column := 0; column < keys; column++ {
+			was := lastMask&(1<<column) > 0
+			is := mask&(1<<column) > 0
+
+			if was != is {
+				events = append(events, keyEvent{
+					time:    time,
+					column:  column,
+					pressed: is,
+				})
+			}
+		}
+
+		lastMask = mask
+	}
+
+	// Frames with negative delta can appear in osu!stable replays
+	slices.SortStableFunc(events, func(a, b keyEvent) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	return
+}
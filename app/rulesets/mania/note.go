@@ -0,0 +1,117 @@
+package mania
+
+import (
+	"cmp"
+	"errors"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	typeNote     = 1
+	typeHoldNote = 128
+
+	maxKeys = 18 // osu! supports up to 9K, 18 keys are possible in co-op mode
+)
+
+type Note struct {
+	ID     int64
+	Column int
+
+	StartTime float64
+	EndTime   float64
+
+	Hold bool
+}
+
+// ParseNotes reads column based notes from beatmap's HitObjects section. Only native osu!mania beatmaps are supported
+func ParseNotes(beatMap *beatmap.BeatMap) (notes []*Note, keys int, err error) {
+	if beatMap.Mode != 3 {
+		return nil, 0, errors.New("only osu!mania beatmaps are supported, converts are not")
+	}
+
+	keys = mutils.Clamp(int(math.Round(beatMap.Diff.GetBaseCS())), 1, maxKeys)
+
+	file, err := os.Open(filepath.Join(settings.General.GetSongsDir(), beatMap.Dir, beatMap.File))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer file.Close()
+
+	scanner := files.NewScanner(file)
+
+	var currentSection string
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "[") {
+			currentSection = strings.Trim(line, "[]")
+			continue
+		}
+
+		if currentSection != "HitObjects" || line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if note := parseNote(strings.Split(line, ","), keys); note != nil {
+			notes = append(notes, note)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	slices.SortStableFunc(notes, func(a, b *Note) int {
+		return cmp.Compare(a.StartTime, b.StartTime)
+	})
+
+	for i, n := range notes {
+		n.ID = int64(i)
+	}
+
+	return notes, keys, nil
+}
+
+func parseNote(data []string, keys int) *Note {
+	if len(data) < 5 {
+		return nil
+	}
+
+	x, _ := strconv.ParseFloat(data[0], 64)
+	time, _ := strconv.ParseFloat(data[2], 64)
+	objType, _ := strconv.Atoi(data[3])
+
+	note := &Note{
+		Column:    mutils.Clamp(int(math.Floor(x*float64(keys)/512)), 0, keys-1),
+		StartTime: time,
+		EndTime:   time,
+	}
+
+	switch {
+	case objType&typeHoldNote > 0:
+		if len(data) < 6 {
+			return nil
+		}
+
+		endTime, _ := strconv.ParseFloat(strings.Split(data[5], ":")[0], 64)
+
+		note.EndTime = max(time, endTime)
+		note.Hold = true
+	case objType&typeNote > 0:
+	default:
+		return nil
+	}
+
+	return note
+}
@@ -0,0 +1,373 @@
+package mania
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets"
+	"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/rplpa"
+	"log"
+	"math"
+)
+
+// tailLeniency widens hit windows of hold note tails in osu!lazer replays
+const tailLeniency = 1.5
+
+type NoteState struct {
+	*Note
+
+	HeadResult HitResult
+	TailResult HitResult
+
+	Holding bool
+
+	// osu!stable judges hold notes once, at release, so head's hit error is kept until then
+	headError float64
+}
+
+// IsDone returns true if note was fully judged
+func (state *NoteState) IsDone() bool {
+	if state.Hold {
+		return state.TailResult != Ignore
+	}
+
+	return state.HeadResult != Ignore
+}
+
+type Judgement struct {
+	Time     int64
+	Column   int
+	Result   HitResult
+	HitError float64
+	Tail     bool
+}
+
+type hitListener func(judgement Judgement, score *Score)
+
+type ManiaRuleSet struct {
+	beatMap *beatmap.BeatMap
+	diff    *difficulty.Difficulty
+
+	keys  int
+	notes []*NoteState
+
+	// pending notes of each column, in order
+	columns [][]*NoteState
+
+	// lazer replays judge hold note heads and tails separately, osu!stable judges the whole hold note once
+	lazer bool
+
+	windows     HitWindows
+	tailWindows HitWindows
+
+	events     []keyEvent
+	eventIndex int
+	pressed    []bool
+
+	score     *Score
+	hitErrors []float64
+
+	listener hitListener
+
+	lastTime int64
+	ended    bool
+}
+
+func NewManiaRuleSet(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty, replay *rplpa.Replay) (*ManiaRuleSet, error) {
+	notes, keys, err := ParseNotes(beatMap)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ManiaRuleSet{
+		beatMap:  beatMap,
+		diff:     diff,
+		keys:     keys,
+		columns:  make([][]*NoteState, keys),
+		windows:  NewHitWindows(diff),
+		pressed:  make([]bool, keys),
+		lastTime: math.MinInt64,
+		lazer:    diff.CheckModActive(difficulty.Lazer) || (replay != nil && replay.OsuVersion >= 30000000),
+	}
+
+	set.tailWindows = set.windows.scale(tailLeniency)
+
+	judgements := 0
+
+	for _, n := range notes {
+		if diff.CheckModActive(difficulty.Mirror) {
+			n.Column = keys - 1 - n.Column
+		}
+
+		state := &NoteState{Note: n}
+
+		set.notes = append(set.notes, state)
+		set.columns[n.Column] = append(set.columns[n.Column], state)
+
+		judgements++
+		if n.Hold && set.lazer {
+			judgements++
+		}
+	}
+
+	set.score = newScore(judgements, diff)
+
+	if replay != nil {
+		set.events = buildKeyEvents(replay.ReplayData, keys)
+	}
+
+	log.Println(fmt.Sprintf("osu!mania ruleset: %dK, %d notes, %d key events", keys, len(notes), len(set.events)))
+
+	return set, nil
+}
+
+func (set *ManiaRuleSet) Update(time int64) {
+	if time <= set.lastTime {
+		return
+	}
+
+	set.lastTime = time
+
+	for set.eventIndex < len(set.events) && set.events[set.eventIndex].time <= float64(time) {
+		event := set.events[set.eventIndex]
+
+		set.updateMisses(event.time)
+
+		if event.pressed {
+			set.press(event.column, event.time)
+		} else {
+			set.release(event.column, event.time)
+		}
+
+		set.pressed[event.column] = event.pressed
+
+		set.eventIndex++
+	}
+
+	set.updateMisses(float64(time))
+
+	if !set.ended && set.isFinished() {
+		set.printEndTable()
+
+		set.ended = true
+	}
+}
+
+func (set *ManiaRuleSet) press(column int, time float64) {
+	for _, state := range set.columns[column] {
+		if state.HeadResult != Ignore {
+			continue
+		}
+
+		hitError := time - state.StartTime
+
+		result := set.windows.ResultFor(hitError)
+		if result == Ignore {
+			return
+		}
+
+		switch {
+		case state.Hold && !set.lazer:
+			if result == Miss {
+				set.judgeHold(state, column, time, Miss, hitError)
+			} else {
+				state.HeadResult = result
+				state.headError = hitError
+				state.Holding = true
+
+				set.hitErrors = append(set.hitErrors, hitError)
+			}
+		case state.Hold:
+			set.judge(state, column, time, result, hitError, false)
+
+			if result == Miss {
+				set.judge(state, column, time, Miss, 0, true)
+			} else {
+				state.Holding = true
+			}
+		default:
+			set.judge(state, column, time, result, hitError, false)
+		}
+
+		set.cleanColumn(column)
+
+		return
+	}
+}
+
+func (set *ManiaRuleSet) release(column int, time float64) {
+	for _, state := range set.columns[column] {
+		if !state.Holding {
+			continue
+		}
+
+		state.Holding = false
+
+		hitError := time - state.EndTime
+
+		if !set.lazer {
+			if hitError < -set.windows.W50 { // Released too early
+				set.judgeHold(state, column, time, Hit50, state.headError)
+			} else {
+				set.judgeHold(state, column, time, set.windows.HoldResultFor(state.headError, hitError), state.headError)
+			}
+
+			set.cleanColumn(column)
+
+			return
+		}
+
+		result := set.tailWindows.ResultFor(hitError)
+		if result == Ignore || hitError < -set.tailWindows.W50 { // Released too early
+			result = Miss
+		}
+
+		set.judge(state, column, time, result, hitError, true)
+		set.cleanColumn(column)
+
+		return
+	}
+}
+
+func (set *ManiaRuleSet) updateMisses(time float64) {
+	for column, states := range set.columns {
+		for _, state := range states {
+			if state.HeadResult == Ignore && time > state.StartTime+set.windows.W50 {
+				switch {
+				case state.Hold && !set.lazer:
+					set.judgeHold(state, column, state.StartTime+set.windows.W50, Miss, 0)
+				case state.Hold:
+					set.judge(state, column, state.StartTime+set.windows.W50, Miss, 0, false)
+					set.judge(state, column, state.StartTime+set.windows.W50, Miss, 0, true)
+				default:
+					set.judge(state, column, state.StartTime+set.windows.W50, Miss, 0, false)
+				}
+			}
+
+			// Hold note held through its end
+			if state.Holding && time >= state.EndTime {
+				state.Holding = false
+
+				if set.lazer {
+					set.judge(state, column, state.EndTime, HitMax, 0, true)
+				} else {
+					set.judgeHold(state, column, state.EndTime, set.windows.HoldResultFor(state.headError, 0), state.headError)
+				}
+			}
+		}
+
+		set.cleanColumn(column)
+	}
+}
+
+func (set *ManiaRuleSet) cleanColumn(column int) {
+	states := set.columns[column]
+
+	for len(states) > 0 && states[0].IsDone() {
+		states = states[1:]
+	}
+
+	set.columns[column] = states
+}
+
+func (set *ManiaRuleSet) judge(state *NoteState, column int, time float64, result HitResult, hitError float64, tail bool) {
+	if tail {
+		state.TailResult = result
+	} else {
+		state.HeadResult = result
+
+		if result != Miss {
+			set.hitErrors = append(set.hitErrors, hitError)
+		}
+	}
+
+	set.score.AddResult(result)
+
+	if set.listener != nil {
+		set.listener(Judgement{
+			Time:     int64(time),
+			Column:   column,
+			Result:   result,
+			HitError: hitError,
+			Tail:     tail,
+		}, set.score)
+	}
+}
+
+// judgeHold gives the single osu!stable judgement of a hold note, released too early hold notes break combo
+func (set *ManiaRuleSet) judgeHold(state *NoteState, column int, time float64, result HitResult, hitError float64) {
+	state.HeadResult = result
+	state.TailResult = result
+
+	set.score.addResult(result, result == Miss || time < state.EndTime-set.windows.W50)
+
+	if set.listener != nil {
+		set.listener(Judgement{
+			Time:     int64(time),
+			Column:   column,
+			Result:   result,
+			HitError: hitError,
+		}, set.score)
+	}
+}
+
+func (set *ManiaRuleSet) isFinished() bool {
+	for _, states := range set.columns {
+		if len(states) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *ManiaRuleSet) printEndTable() {
+	rulesets.LogEndTable([]string{"Score", "Accuracy", "Grade", "MAX", "300", "200", "100", "50", "Miss", "Max Combo", "Mods", "UR"}, []string{
+		utils.Humanize(set.score.Score),
+		fmt.Sprintf("%.2f", set.score.Accuracy*100),
+		set.score.Grade.String(),
+		utils.Humanize(set.score.CountMax),
+		utils.Humanize(set.score.Count300),
+		utils.Humanize(set.score.Count200),
+		utils.Humanize(set.score.Count100),
+		utils.Humanize(set.score.Count50),
+		utils.Humanize(set.score.CountMiss),
+		utils.Humanize(set.score.Combo),
+		set.diff.GetModString(),
+		fmt.Sprintf("%.2f", set.GetUnstableRate()),
+	})
+}
+
+// GetUnstableRate returns unstable rate of note heads, adjusted by rate changing mods
+func (set *ManiaRuleSet) GetUnstableRate() float64 {
+	return rulesets.UnstableRate(set.hitErrors, set.diff.GetSpeed())
+}
+
+func (set *ManiaRuleSet) SetListener(listener hitListener) {
+	set.listener = listener
+}
+
+func (set *ManiaRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+func (set *ManiaRuleSet) IsPressed(column int) bool {
+	return set.pressed[column]
+}
+
+func (set *ManiaRuleSet) GetKeys() int {
+	return set.keys
+}
+
+func (set *ManiaRuleSet) GetNotes() []*NoteState {
+	return set.notes
+}
+
+func (set *ManiaRuleSet) GetScore() *Score {
+	return set.score
+}
+
+func (set *ManiaRuleSet) GetDifficulty() *difficulty.Difficulty {
+	return set.diff
+}
@@ -0,0 +1,147 @@
+package mania
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const maxScore = 1000000
+
+type Score struct {
+	Score        int64
+	Accuracy     float64
+	Grade        osu.Grade
+	CurrentCombo uint
+	Combo        uint
+	CountMax     uint
+	Count300     uint
+	Count200     uint
+	Count100     uint
+	Count50      uint
+	CountMiss    uint
+
+	judgements    uint
+	maxJudgements uint
+
+	bonus      float64
+	baseScore  float64
+	bonusScore float64
+
+	modMultiplier float64
+	silver        bool
+}
+
+func newScore(maxJudgements int, diff *difficulty.Difficulty) *Score {
+	score := &Score{
+		Accuracy:      1,
+		maxJudgements: uint(max(maxJudgements, 1)),
+		bonus:         100,
+		modMultiplier: getModMultiplier(diff),
+		silver:        diff.CheckModActive(difficulty.Hidden | difficulty.Flashlight | difficulty.FadeIn),
+	}
+
+	score.calculateGrade()
+
+	return score
+}
+
+// getModMultiplier returns osu!stable's osu!mania score multiplier, difficulty increasing mods are not rewarded
+func getModMultiplier(diff *difficulty.Difficulty) float64 {
+	mult := 1.0
+
+	for _, mod := range []difficulty.Modifier{difficulty.Easy, difficulty.NoFail, difficulty.HalfTime} {
+		if diff.CheckModActive(mod) {
+			mult *= 0.5
+		}
+	}
+
+	return mult
+}
+
+// AddResult applies osu!stable's osu!mania ScoreV1 rules, half of the score comes from judgements, the other half from the bonus meter
+func (s *Score) AddResult(result HitResult) {
+	s.addResult(result, result == Miss)
+}
+
+func (s *Score) addResult(result HitResult, comboBreak bool) {
+	if result == Ignore {
+		return
+	}
+
+	switch result {
+	case HitMax:
+		s.CountMax++
+	case Hit300:
+		s.Count300++
+	case Hit200:
+		s.Count200++
+	case Hit100:
+		s.Count100++
+	case Hit50:
+		s.Count50++
+	case Miss:
+		s.CountMiss++
+	}
+
+	if comboBreak {
+		s.CurrentCombo = 0
+	} else {
+		s.CurrentCombo++
+		s.Combo = max(s.Combo, s.CurrentCombo)
+	}
+
+	s.judgements++
+
+	bonusValue, bonusChange := result.bonus()
+
+	s.bonus = mutils.Clamp(s.bonus+bonusChange, 0, 100)
+
+	perJudgement := float64(maxScore) * s.modMultiplier * 0.5 / float64(s.maxJudgements)
+
+	s.baseScore += perJudgement * result.value() / 320
+	s.bonusScore += perJudgement * bonusValue * math.Sqrt(s.bonus) / 320
+
+	s.Score = int64(math.Round(s.baseScore + s.bonusScore))
+
+	s.calculateAccuracy()
+	s.calculateGrade()
+}
+
+func (s *Score) calculateAccuracy() {
+	if s.judgements == 0 {
+		s.Accuracy = 1
+		return
+	}
+
+	points := 300*(s.CountMax+s.Count300) + 200*s.Count200 + 100*s.Count100 + 50*s.Count50
+
+	s.Accuracy = float64(points) / float64(300*s.judgements)
+}
+
+func (s *Score) calculateGrade() {
+	switch {
+	case s.Accuracy == 1:
+		s.Grade = osu.SS
+	case s.Accuracy > 0.95:
+		s.Grade = osu.S
+	case s.Accuracy > 0.9:
+		s.Grade = osu.A
+	case s.Accuracy > 0.8:
+		s.Grade = osu.B
+	case s.Accuracy > 0.7:
+		s.Grade = osu.C
+	default:
+		s.Grade = osu.D
+	}
+
+	if s.silver {
+		switch s.Grade {
+		case osu.S:
+			s.Grade = osu.SH
+		case osu.SS:
+			s.Grade = osu.SSH
+		}
+	}
+}
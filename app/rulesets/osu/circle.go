@@ -67,12 +67,12 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 						if hit == Miss {
 							combo = Reset
 						} else {
-							if len(circle.players) == 1 {
+							if circle.ruleSet.showsFeedback() {
 								circle.hitCircle.PlaySound()
 							}
 						}
 
-						if len(circle.players) == 1 {
+						if circle.ruleSet.showsFeedback() {
 							circle.hitCircle.Arm(hit != Miss, float64(time))
 						}
 
@@ -85,7 +85,7 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 					player.leftCondE = false
 					player.rightCondE = false
 
-					if action == Shake && len(circle.players) == 1 {
+					if action == Shake && circle.ruleSet.showsFeedback() {
 						circle.hitCircle.Shake(float64(time))
 					}
 				}
@@ -105,7 +105,7 @@ func (circle *Circle) UpdatePostFor(player *difficultyPlayer, time int64, _ bool
 		position := circle.hitCircle.GetStackedPositionAtMod(float64(time), player.diff)
 		circle.ruleSet.SendResult(player.cursor, createJudgementResult(Miss, Hit300, Reset, time, position, circle))
 
-		if len(circle.players) == 1 {
+		if circle.ruleSet.showsFeedback() {
 			circle.hitCircle.Arm(false, float64(time))
 		}
 
@@ -135,7 +135,7 @@ func (circle *Circle) MissForcefully(player *difficultyPlayer, time int64) {
 	if !state.isHit {
 		position := circle.hitCircle.GetStackedPositionAtMod(float64(time), player.diff)
 
-		if len(circle.players) == 1 {
+		if circle.ruleSet.showsFeedback() {
 			circle.hitCircle.Arm(false, float64(time))
 		}
 
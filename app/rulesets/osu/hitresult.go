@@ -42,6 +42,56 @@ const (
 	RawHits     = SliderHits | SpinnerHits
 )
 
+func (r HitResult) String() string {
+	var name string
+
+	switch r & (^Additions) {
+	case Ignore:
+		name = "Ignore"
+	case SliderMiss:
+		name = "SliderMiss"
+	case Miss:
+		name = "Miss"
+	case Hit50:
+		name = "Hit50"
+	case Hit100:
+		name = "Hit100"
+	case Hit300:
+		name = "Hit300"
+	case SliderStart:
+		name = "SliderStart"
+	case SliderPoint:
+		name = "SliderPoint"
+	case SliderRepeat:
+		name = "SliderRepeat"
+	case LegacySliderEnd:
+		name = "LegacySliderEnd"
+	case SliderEnd:
+		name = "SliderEnd"
+	case SliderFinish:
+		name = "SliderFinish"
+	case SpinnerSpin:
+		name = "SpinnerSpin"
+	case SpinnerPoints:
+		name = "SpinnerPoints"
+	case SpinnerBonus:
+		name = "SpinnerBonus"
+	case PositionalMiss:
+		name = "PositionalMiss"
+	default:
+		name = "Unknown"
+	}
+
+	switch {
+	case r&GekiAddition > 0:
+		name += "g"
+	case r&KatuAddition > 0:
+		name += "k"
+	}
+
+	return name
+}
+
 func (r HitResult) IsBonus() bool {
 	v := r & (^Additions)
 
@@ -45,6 +45,17 @@ type StrainPeaks struct {
 	Total []float64
 }
 
+// ObjectStrains contains strains of every hit object. First object is always 0 as it's not processed by skills
+type ObjectStrains struct {
+	Aim        []float64
+	Speed      []float64
+	Flashlight []float64
+
+	// AimDifficultStrains and SpeedDifficultStrains contain contributions of each object to AimDifficultStrainCount and SpeedDifficultStrainCount
+	AimDifficultStrains   []float64
+	SpeedDifficultStrains []float64
+}
+
 type PPv2Results struct {
 	Aim, Speed, Acc, Flashlight, Total float64
 }
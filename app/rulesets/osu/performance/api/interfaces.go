@@ -17,6 +17,11 @@ type IDifficultyCalculator interface {
 	GetVersionMessage() string
 }
 
+// IObjectStrainCalculator is implemented by difficulty calculators that can expose strains of every object
+type IObjectStrainCalculator interface {
+	CalculateObjectStrains(objects []objects.IHitObject, diff *difficulty.Difficulty) ObjectStrains
+}
+
 type IPerformanceCalculator interface {
 	Calculate(attribs Attributes, score PerfScore, diff *difficulty.Difficulty) PPv2Results
 }
@@ -0,0 +1,50 @@
+package performance
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+)
+
+// Comparison holds star rating and pp calculated with a single pp version
+type Comparison struct {
+	Version string
+	Stars   api.Attributes
+	PP      api.PPv2Results
+}
+
+// CompareVersions calculates star rating and pp of the score with every bundled pp version
+func CompareVersions(objs []objects.IHitObject, diff *difficulty.Difficulty, score api.PerfScore) []Comparison {
+	results := make([]Comparison, 0, len(Versions))
+
+	for _, version := range Versions {
+		diff.DiffCalcMode = true // To use lazer's stack offset for stable plays without having to put LZ mod
+
+		stars := version.NewDifficultyCalculator().CalculateSingle(objs, diff)
+
+		diff.DiffCalcMode = false
+
+		results = append(results, Comparison{
+			Version: version.Name,
+			Stars:   stars,
+			PP:      version.NewPPCalculator().Calculate(stars, score, diff),
+		})
+	}
+
+	return results
+}
+
+// CalculateVersionSteps runs CalculateStep with every bundled pp version, order matches Versions
+func CalculateVersionSteps(objs []objects.IHitObject, diff *difficulty.Difficulty) [][]api.Attributes {
+	results := make([][]api.Attributes, 0, len(Versions))
+
+	for _, version := range Versions {
+		diff.DiffCalcMode = true
+
+		results = append(results, version.NewDifficultyCalculator().CalculateStep(objs, diff))
+
+		diff.DiffCalcMode = false
+	}
+
+	return results
+}
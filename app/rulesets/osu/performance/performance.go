@@ -9,6 +9,33 @@ import (
 	"github.com/wieku/danser-go/app/settings"
 )
 
+// Version holds constructors of one of the bundled pp versions
+type Version struct {
+	Name string
+
+	NewDifficultyCalculator func() api.IDifficultyCalculator
+	NewPPCalculator         func() api.IPerformanceCalculator
+}
+
+// Versions contains all bundled pp versions, from the oldest to the latest
+var Versions = []Version{
+	{"211112", pp211112.NewDifficultyCalculator, pp211112.NewPPCalculator},
+	{"220930", pp220930.NewDifficultyCalculator, pp220930.NewPPCalculator},
+	{"241007", pp241007.NewDifficultyCalculator, pp241007.NewPPCalculator},
+	{"250306", pp250306.NewDifficultyCalculator, pp250306.NewPPCalculator},
+}
+
+// GetVersion returns bundled pp version by its name, latest version is returned if name is unknown
+func GetVersion(name string) Version {
+	for _, v := range Versions {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	return Versions[len(Versions)-1]
+}
+
 var diffCalcInit func() api.IDifficultyCalculator
 var ppCalcInit func() api.IPerformanceCalculator
 
@@ -17,20 +44,10 @@ func initConstructors() {
 		return
 	}
 
-	switch settings.Gameplay.PPVersion {
-	case "211112":
-		diffCalcInit = pp211112.NewDifficultyCalculator
-		ppCalcInit = pp211112.NewPPCalculator
-	case "220930":
-		diffCalcInit = pp220930.NewDifficultyCalculator
-		ppCalcInit = pp220930.NewPPCalculator
-	case "241007":
-		diffCalcInit = pp241007.NewDifficultyCalculator
-		ppCalcInit = pp241007.NewPPCalculator
-	default:
-		diffCalcInit = pp250306.NewDifficultyCalculator
-		ppCalcInit = pp250306.NewPPCalculator
-	}
+	version := GetVersion(settings.Gameplay.PPVersion)
+
+	diffCalcInit = version.NewDifficultyCalculator
+	ppCalcInit = version.NewPPCalculator
 }
 
 var diffCalc api.IDifficultyCalculator
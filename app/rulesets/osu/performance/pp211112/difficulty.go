@@ -204,6 +204,47 @@ func (diffCalc *DifficultyCalculator) CalculateStrainPeaks(objects []objects.IHi
 	return peaks
 }
 
+// CalculateObjectStrains calculates strains of every object. This version doesn't count difficult strains,
+// so their contributions are left empty
+func (diffCalc *DifficultyCalculator) CalculateObjectStrains(objects []objects.IHitObject, diff *difficulty.Difficulty) api.ObjectStrains {
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff, false)
+
+	aimSkill := skills2.NewAimSkill(diff, true, false)
+	speedSkill := skills2.NewSpeedSkill(diff, false)
+	flashlightSkill := skills2.NewFlashlightSkill(diff, false)
+
+	strains := api.ObjectStrains{
+		Aim:                   make([]float64, len(objects)),
+		Speed:                 make([]float64, len(objects)),
+		Flashlight:            make([]float64, len(objects)),
+		AimDifficultStrains:   make([]float64, len(objects)),
+		SpeedDifficultStrains: make([]float64, len(objects)),
+	}
+
+	// Speed bonus has side effects, so it's captured when the skill calculates it instead of calling it again
+	speedBonus := 1.0
+
+	strainBonusOf := speedSkill.StrainBonusOf
+
+	speedSkill.StrainBonusOf = func(obj *preprocessing.DifficultyObject) float64 {
+		speedBonus = strainBonusOf(obj)
+		return speedBonus
+	}
+
+	for i, o := range diffObjects {
+		aimSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+
+		// Strains accumulate with decay in this version, so the object's strain is the skill's strain after processing it
+		strains.Aim[i+1] = aimSkill.CurrentStrain
+		strains.Speed[i+1] = speedSkill.CurrentStrain * speedBonus
+		strains.Flashlight[i+1] = flashlightSkill.CurrentStrain
+	}
+
+	return strains
+}
+
 func (diffCalc *DifficultyCalculator) GetVersion() int {
 	return CurrentVersion
 }
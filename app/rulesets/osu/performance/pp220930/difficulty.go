@@ -210,6 +210,47 @@ func (diffCalc *DifficultyCalculator) CalculateStrainPeaks(objects []objects.IHi
 	return peaks
 }
 
+// CalculateObjectStrains calculates strains of every object. This version doesn't count difficult strains,
+// so their contributions are left empty
+func (diffCalc *DifficultyCalculator) CalculateObjectStrains(objects []objects.IHitObject, diff *difficulty.Difficulty) api.ObjectStrains {
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(diff, true)
+	speedSkill := skills.NewSpeedSkill(diff)
+	flashlightSkill := skills.NewFlashlightSkill(diff)
+
+	strains := api.ObjectStrains{
+		Aim:                   make([]float64, len(objects)),
+		Speed:                 make([]float64, len(objects)),
+		Flashlight:            make([]float64, len(objects)),
+		AimDifficultStrains:   make([]float64, len(objects)),
+		SpeedDifficultStrains: make([]float64, len(objects)),
+	}
+
+	record := func(skill *skills.Skill, target []float64) {
+		strainValueOf := skill.StrainValueOf
+
+		skill.StrainValueOf = func(obj *preprocessing.DifficultyObject) float64 {
+			strain := strainValueOf(obj)
+			target[obj.Index+1] = strain
+
+			return strain
+		}
+	}
+
+	record(aimSkill.Skill, strains.Aim)
+	record(speedSkill.Skill, strains.Speed)
+	record(flashlightSkill.Skill, strains.Flashlight)
+
+	for _, o := range diffObjects {
+		aimSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+	}
+
+	return strains
+}
+
 func (diffCalc *DifficultyCalculator) GetVersion() int {
 	return CurrentVersion
 }
@@ -204,6 +204,49 @@ func (diffCalc *DifficultyCalculator) CalculateStrainPeaks(objects []objects.IHi
 	return peaks
 }
 
+// CalculateObjectStrains calculates strains of every object and their contributions to difficult strain counts
+func (diffCalc *DifficultyCalculator) CalculateObjectStrains(objects []objects.IHitObject, diff *difficulty.Difficulty) api.ObjectStrains {
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(diff, true, false)
+	speedSkill := skills.NewSpeedSkill(diff, false)
+	flashlightSkill := skills.NewFlashlightSkill(diff)
+
+	strains := api.ObjectStrains{
+		Aim:                   make([]float64, len(objects)),
+		Speed:                 make([]float64, len(objects)),
+		Flashlight:            make([]float64, len(objects)),
+		AimDifficultStrains:   make([]float64, len(objects)),
+		SpeedDifficultStrains: make([]float64, len(objects)),
+	}
+
+	record := func(skill *skills.Skill, target []float64) {
+		strainValueOf := skill.StrainValueOf
+
+		skill.StrainValueOf = func(obj *preprocessing.DifficultyObject) float64 {
+			strain := strainValueOf(obj)
+			target[obj.Index+1] = strain
+
+			return strain
+		}
+	}
+
+	record(aimSkill.Skill, strains.Aim)
+	record(speedSkill.Skill, strains.Speed)
+	record(flashlightSkill.Skill, strains.Flashlight)
+
+	for _, o := range diffObjects {
+		aimSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+	}
+
+	copy(strains.AimDifficultStrains[1:], aimSkill.GetDifficultStrains())
+	copy(strains.SpeedDifficultStrains[1:], speedSkill.GetDifficultStrains())
+
+	return strains
+}
+
 func (diffCalc *DifficultyCalculator) GetVersion() int {
 	return CurrentVersion
 }
@@ -83,7 +83,7 @@ func (skill *Skill) Process(current *preprocessing.DifficultyObject) {
 	if skill.lastDifficulty != skill.difficulty {
 		skill.difficultStrainCount = skill.countDifficultStrains()
 	} else if skill.difficulty != 0 {
-		skill.difficultStrainCount += 1.1 / (1 + math.Exp(-10*(currentStrain/(skill.difficulty/10)-0.88)))
+		skill.difficultStrainCount += difficultStrainWeight(currentStrain, skill.difficulty/10)
 	}
 
 	skill.lastDifficulty = skill.difficulty
@@ -187,12 +187,29 @@ func (skill *Skill) countDifficultStrains() float64 {
 	sum := 0.0
 
 	for _, s := range skill.objectStrains {
-		sum += 1.1 / (1 + math.Exp(-10*(s/consistentTopStrain-0.88)))
+		sum += difficultStrainWeight(s, consistentTopStrain)
 	}
 
 	return sum
 }
 
+// GetDifficultStrains returns contribution of every processed object to difficult strain count
+func (skill *Skill) GetDifficultStrains() []float64 {
+	difficultStrains := make([]float64, len(skill.objectStrains))
+
+	if diff := skill.DifficultyValue(); diff > 0 {
+		for i, s := range skill.objectStrains {
+			difficultStrains[i] = difficultStrainWeight(s, diff/10)
+		}
+	}
+
+	return difficultStrains
+}
+
+func difficultStrainWeight(strain, consistentTopStrain float64) float64 {
+	return 1.1 / (1 + math.Exp(-10*(strain/consistentTopStrain-0.88)))
+}
+
 func (skill *Skill) CountDifficultStrains() float64 {
 	if skill.stepCalc {
 		return skill.difficultStrainCount
@@ -119,6 +119,8 @@ type OsuRuleSet struct {
 
 	oppDiffs map[string][]api.Attributes
 
+	versionDiffs map[string][][]api.Attributes
+
 	queue         []HitObject
 	processed     []HitObject
 	hitListener   hitListener
@@ -133,6 +135,7 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, diffs [
 	ruleset := new(OsuRuleSet)
 	ruleset.beatMap = beatMap
 	ruleset.oppDiffs = make(map[string][]api.Attributes)
+	ruleset.versionDiffs = make(map[string][][]api.Attributes)
 
 	log.Println("Using pp calc version", performance.GetDifficultyCalculator().GetVersionMessage())
 
@@ -540,7 +543,7 @@ func (set *OsuRuleSet) SendResult(cursor *graphics.Cursor, judgementResult Judge
 		set.hitListener(cursor, judgementResult, *subSet.score)
 	}
 
-	if len(set.cursors) == 1 && judgementResult.HitResult != SliderFinish && !settings.RECORD {
+	if len(set.cursors) == 1 && judgementResult.HitResult != SliderFinish && !settings.RECORD && !settings.HEADLESS {
 		log.Println(fmt.Sprintf(
 			"Got: %3d, Combo: %4d, Max Combo: %4d, Score: %9d, Acc: %6.2f%%, 300: %4d, 100: %3d, 50: %2d, miss: %2d, from: %d, at: %d, pos: %.0fx%.0f, pp: %.2f",
 			judgementResult.HitResult.ScoreValueMod(subSet.player.diff.Mods),
@@ -607,6 +610,11 @@ func (set *OsuRuleSet) processGekiKatu(sSet *subSet, judgementResult *JudgementR
 	}
 }
 
+// showsFeedback returns true if judgements should be reflected on beatmap objects (animations, hitsounds)
+func (set *OsuRuleSet) showsFeedback() bool {
+	return len(set.cursors) == 1 && !settings.HEADLESS
+}
+
 func (set *OsuRuleSet) CanBeHit(time int64, object HitObject, player *difficultyPlayer) ClickAction {
 	var clickAction ClickAction
 
@@ -774,6 +782,11 @@ func (set *OsuRuleSet) PlayerStopped(cursor *graphics.Cursor, time int64) {
 	}
 }
 
+// IsEnded returns true if all objects were judged
+func (set *OsuRuleSet) IsEnded() bool {
+	return set.ended
+}
+
 func (set *OsuRuleSet) SetListener(listener hitListener) {
 	set.hitListener = listener
 }
@@ -850,6 +863,63 @@ func (set *OsuRuleSet) GetFinalDiffAttribs(cursor *graphics.Cursor) api.Attribut
 	return set.oppDiffs[subSet.player.maskedModString][len(set.oppDiffs[subSet.player.maskedModString])-1]
 }
 
+// PrepareVersionComparison calculates star ratings for player's mods with every bundled pp version
+func (set *OsuRuleSet) PrepareVersionComparison(cursor *graphics.Cursor) {
+	subSet := set.cursors[cursor]
+
+	if set.versionDiffs[subSet.player.maskedModString] != nil {
+		return
+	}
+
+	set.versionDiffs[subSet.player.maskedModString] = performance.CalculateVersionSteps(set.beatMap.HitObjects, subSet.player.diff)
+}
+
+// GetVersionComparison returns current star rating and pp of the player with every bundled pp version.
+// Returns nil if PrepareVersionComparison wasn't called before.
+func (set *OsuRuleSet) GetVersionComparison(cursor *graphics.Cursor) []performance.Comparison {
+	subSet := set.cursors[cursor]
+
+	steps := set.versionDiffs[subSet.player.maskedModString]
+	if steps == nil {
+		return nil
+	}
+
+	index := max(1, subSet.score.scoredObjects) - 1
+
+	results := make([]performance.Comparison, 0, len(performance.Versions))
+
+	for i, version := range performance.Versions {
+		stars := steps[i][min(int(index), len(steps[i])-1)]
+
+		results = append(results, performance.Comparison{
+			Version: version.Name,
+			Stars:   stars,
+			PP:      version.NewPPCalculator().Calculate(stars, subSet.score.ToPerfScore(), subSet.player.diff),
+		})
+	}
+
+	return results
+}
+
+// GetFinalVersionDiffAttribs returns star ratings of the whole beatmap with every bundled pp version.
+// Returns nil if PrepareVersionComparison wasn't called before.
+func (set *OsuRuleSet) GetFinalVersionDiffAttribs(cursor *graphics.Cursor) []api.Attributes {
+	subSet := set.cursors[cursor]
+
+	steps := set.versionDiffs[subSet.player.maskedModString]
+	if steps == nil {
+		return nil
+	}
+
+	results := make([]api.Attributes, 0, len(steps))
+
+	for _, s := range steps {
+		results = append(results, s[len(s)-1])
+	}
+
+	return results
+}
+
 func (set *OsuRuleSet) GetScore(cursor *graphics.Cursor) Score {
 	return *(set.cursors[cursor].score)
 }
@@ -859,6 +929,10 @@ func (set *OsuRuleSet) GetHP(cursor *graphics.Cursor) float64 {
 	return subSet.hp.GetHealth()
 }
 
+func (set *OsuRuleSet) IsFailed(cursor *graphics.Cursor) bool {
+	return set.cursors[cursor].failed
+}
+
 func (set *OsuRuleSet) GetPlayer(cursor *graphics.Cursor) *difficultyPlayer {
 	subSet := set.cursors[cursor]
 	return subSet.player
@@ -153,7 +153,7 @@ func (slider *Slider) UpdateClickFor(player *difficultyPlayer, time int64) bool
 				}
 
 				if hit != Ignore {
-					if len(slider.players) == 1 {
+					if slider.ruleSet.showsFeedback() {
 						slider.hitSlider.HitEdge(0, float64(time), hit != SliderMiss)
 					}
 
@@ -213,7 +213,7 @@ func (slider *Slider) lazerPostHeadProcess(player *difficultyPlayer, state *slid
 		state.sliding = true
 		state.slideStart = time
 
-		if len(slider.players) == 1 {
+		if slider.ruleSet.showsFeedback() {
 			slider.hitSlider.InitSlide(float64(time))
 		}
 	}
@@ -289,7 +289,7 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64, processSli
 			state.sliding = true
 			state.slideStart = time
 
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showsFeedback() {
 				slider.hitSlider.InitSlide(float64(time))
 			}
 		}
@@ -301,7 +301,7 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64, processSli
 		}
 
 		if !allowable && state.sliding && state.scored+state.missed < len(state.points) {
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showsFeedback() {
 				slider.hitSlider.KillSlide(float64(time))
 			}
 
@@ -417,7 +417,7 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64, proces
 	}
 
 	if (time >= int64(slider.hitSlider.GetEndTime()) || (processSliderEndsAhead && int64(slider.hitSlider.GetEndTime())-time == 1)) && !state.isHit {
-		if len(slider.players) == 1 && !state.isStartHit && !player.diff.CheckModActive(difficulty.Lazer) {
+		if slider.ruleSet.showsFeedback() && !state.isStartHit && !player.diff.CheckModActive(difficulty.Lazer) {
 			slider.hitSlider.ArmStart(false, float64(time))
 		}
 
@@ -427,7 +427,7 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64, proces
 
 		rate := float64(state.scored) / float64(len(state.points)+1)
 
-		if len(slider.players) == 1 {
+		if slider.ruleSet.showsFeedback() {
 			lzActive := player.diff.CheckModActive(difficulty.Lazer)
 
 			if ((!lzActive || player.lzLegacySound) && rate > 0) || (lzActive && !player.lzLegacySound && state.endScored) {
@@ -477,7 +477,7 @@ func (slider *Slider) processHeadMiss(player *difficultyPlayer, time int64) {
 	state := slider.state[player]
 
 	if time > int64(slider.hitSlider.GetStartTime())+player.diff.Hit50 && !state.isStartHit {
-		if len(slider.players) == 1 && !state.isHit { //don't fade if slider already ended (and armed the start)
+		if slider.ruleSet.showsFeedback() && !state.isHit { //don't fade if slider already ended (and armed the start)
 			slider.hitSlider.ArmStart(false, float64(time))
 		}
 
@@ -522,7 +522,7 @@ func (slider *Slider) MissForcefully(player *difficultyPlayer, time int64) {
 	if !state.isStartHit {
 		position := slider.hitSlider.GetStackedStartPositionMod(player.diff)
 
-		if len(slider.players) == 1 {
+		if slider.ruleSet.showsFeedback() {
 			slider.hitSlider.HitEdge(0, float64(time), false)
 		}
 
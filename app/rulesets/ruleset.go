@@ -0,0 +1,46 @@
+package rulesets
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"log"
+	"math"
+	"strings"
+)
+
+// UnstableRate returns unstable rate of given hit errors, adjusted by rate changing mods
+func UnstableRate(hitErrors []float64, speed float64) float64 {
+	if len(hitErrors) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, e := range hitErrors {
+		mean += e
+	}
+
+	mean /= float64(len(hitErrors))
+
+	variance := 0.0
+	for _, e := range hitErrors {
+		variance += (e - mean) * (e - mean)
+	}
+
+	variance /= float64(len(hitErrors))
+
+	return math.Sqrt(variance) * 10 / speed
+}
+
+// LogEndTable logs results shown at the end of the map
+func LogEndTable(header []string, results []string) {
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader(header)
+
+	table.Append(results)
+
+	table.Render()
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+}
@@ -0,0 +1,70 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"math"
+)
+
+type HitResult int
+
+const (
+	Ignore = HitResult(iota)
+	Miss
+	Ok
+	Great
+)
+
+func (r HitResult) String() string {
+	switch r {
+	case Miss:
+		return "Miss"
+	case Ok:
+		return "100"
+	case Great:
+		return "300"
+	default:
+		return "Ignore"
+	}
+}
+
+// HitWindows are distances from object's time in ms, a hit outside the Miss window is ignored
+type HitWindows struct {
+	Great float64
+	Ok    float64
+	Miss  float64
+}
+
+// NewHitWindows calculates osu!taiko hit windows for given difficulty
+func NewHitWindows(diff *difficulty.Difficulty) HitWindows {
+	od := diff.GetOD()
+
+	if diff.CheckModActive(difficulty.HardRock) {
+		od = min(od*1.4, 10)
+	} else if diff.CheckModActive(difficulty.Easy) {
+		od /= 2
+	}
+
+	return HitWindows{
+		Great: math.Floor(difficulty.DifficultyRate(od, 50, 35, 20)),
+		Ok:    math.Floor(difficulty.DifficultyRate(od, 120, 80, 50)),
+		Miss:  math.Floor(difficulty.DifficultyRate(od, 135, 95, 70)),
+	}
+}
+
+// ResultFor returns judgement for given hit error
+func (windows HitWindows) ResultFor(hitError float64) HitResult {
+	if hitError < 0 {
+		hitError = -hitError
+	}
+
+	switch {
+	case hitError < windows.Great:
+		return Great
+	case hitError < windows.Ok:
+		return Ok
+	case hitError < windows.Miss:
+		return Miss
+	default:
+		return Ignore
+	}
+}
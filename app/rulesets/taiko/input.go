@@ -0,0 +1,71 @@
+package taiko
+
+import (
+	"cmp"
+	"github.com/wieku/rplpa"
+	"slices"
+)
+
+type Action int
+
+const (
+	LeftCentre = Action(iota)
+	RightCentre
+	LeftRim
+	RightRim
+
+	actionCount
+)
+
+// IsRim returns true if action hits a kat
+func (a Action) IsRim() bool {
+	return a == LeftRim || a == RightRim
+}
+
+type keyEvent struct {
+	time    float64
+	action  Action
+	pressed bool
+}
+
+// buildKeyEvents converts replay frames to drum press and release events.
+// In osu!taiko replays M1 and K1 are centre hits, M2 and K2 are rim hits.
+func buildKeyEvents(frames []*rplpa.ReplayData) (events []keyEvent) {
+	time := 0.0
+
+	var last [actionCount]bool
+
+	for _, frame := range frames {
+		if frame.KeyPressed == nil {
+			continue
+		}
+
+		time += frame.Time
+
+		current := [actionCount]bool{
+			LeftCentre:  frame.KeyPressed.LeftClick,
+			RightCentre: frame.KeyPressed.Key1,
+			LeftRim:     frame.KeyPressed.RightClick,
+			RightRim:    frame.KeyPressed.Key2,
+		}
+
+		for a := Action(0); a < actionCount; a++ {
+			if current[a] != last[a] {
+				events = append(events, keyEvent{
+					time:    time,
+					action:  a,
+					pressed: current[a],
+				})
+			}
+		}
+
+		last = current
+	}
+
+	// Frames with negative delta can appear in osu!stable replays
+	slices.SortStableFunc(events, func(a, b keyEvent) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	return
+}
@@ -0,0 +1,185 @@
+package taiko
+
+import (
+	"errors"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const (
+	sampleWhistle = 2
+	sampleFinish  = 4
+	sampleClap    = 8
+
+	// osu!standard beatmaps are generally slower than osu!taiko ones, the same factor is used in osu!lazer
+	convertVelocityMultiplier = 1.4
+
+	swellHitMultiplier = 1.65
+)
+
+type Kind int
+
+const (
+	Hit = Kind(iota)
+	DrumRoll
+	Swell
+)
+
+type Object struct {
+	ID   int64
+	Kind Kind
+
+	StartTime float64
+	EndTime   float64
+
+	Kat    bool
+	Strong bool
+
+	// Velocity is scroll speed of the object in osu!pixels per millisecond
+	Velocity float64
+
+	// Ticks are times of drum roll ticks
+	Ticks []float64
+
+	// RequiredHits is number of hits needed to complete a swell
+	RequiredHits int
+}
+
+// ConvertObjects converts parsed beatmap objects to taiko objects.
+// Circles become dons or kats depending on their hitsounds, sliders become drum rolls and spinners become swells.
+// Like in osu!stable, sliders of osu!standard beatmaps that are too fast to be drum rolls become streams of hits.
+func ConvertObjects(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty) ([]*Object, error) {
+	if beatMap.Mode != 0 && beatMap.Mode != 1 {
+		return nil, errors.New("only osu!standard and osu!taiko beatmaps can be played in osu!taiko")
+	}
+
+	velocityMultiplier := 1.0
+	if beatMap.Mode == 0 {
+		velocityMultiplier = convertVelocityMultiplier
+	}
+
+	tickDivisor := 4.0
+	if beatMap.Timings.TickRate == 3 {
+		tickDivisor = 3
+	}
+
+	taikoObjects := make([]*Object, 0, len(beatMap.HitObjects))
+
+	for _, o := range beatMap.HitObjects {
+		point := beatMap.Timings.GetPointAt(o.GetStartTime())
+		beatLength := taikoBeatLength(point)
+
+		obj := &Object{
+			ID:        int64(len(taikoObjects)),
+			StartTime: o.GetStartTime(),
+			EndTime:   o.GetEndTime(),
+			Velocity:  100 * beatMap.Timings.SliderMult * velocityMultiplier / beatLength,
+		}
+
+		switch ho := o.(type) {
+		case *objects.Circle:
+			obj.Kind = Hit
+			obj.Kat = ho.GetSample()&(sampleWhistle|sampleClap) > 0
+			obj.Strong = ho.GetSample()&sampleFinish > 0
+		case *objects.Slider:
+			duration, hitSpacing, toHits := convertSlider(beatMap, ho, point, beatLength)
+
+			if toHits {
+				taikoObjects = appendHitStream(taikoObjects, ho, obj, duration, hitSpacing)
+				continue
+			}
+
+			obj.Kind = DrumRoll
+			obj.Strong = ho.GetBaseSample()&sampleFinish > 0
+			obj.EndTime = obj.StartTime + duration
+
+			tickSpacing := point.GetBaseBeatLength() / tickDivisor
+
+			for t := obj.StartTime; t <= obj.EndTime+1 && tickSpacing > 0; t += tickSpacing {
+				obj.Ticks = append(obj.Ticks, t)
+			}
+		case *objects.Spinner:
+			obj.Kind = Swell
+
+			hitMultiplier := difficulty.DifficultyRate(diff.GetOD(), 3, 5, 7.5) * swellHitMultiplier
+
+			obj.RequiredHits = max(1, int(math.Floor((obj.EndTime-obj.StartTime)/1000*hitMultiplier)))
+		default:
+			continue
+		}
+
+		taikoObjects = append(taikoObjects, obj)
+	}
+
+	return taikoObjects, nil
+}
+
+// taikoBeatLength returns beat length adjusted by slider velocity, osu!taiko allows lower slider velocities than other modes
+func taikoBeatLength(point objects.TimingPoint) float64 {
+	raw := point.GetRawBeatLength()
+	if raw >= 0 || math.IsNaN(raw) {
+		return point.GetBaseBeatLength()
+	}
+
+	return point.GetBaseBeatLength() * float64(float32(mutils.Clamp(-raw, 10, 10000))/100)
+}
+
+// convertSlider returns duration of the drum roll made from the slider, calculated with taiko velocity like in osu!stable.
+// Sliders of osu!standard beatmaps shorter than two beats become streams of hits spaced by hitSpacing.
+func convertSlider(beatMap *beatmap.BeatMap, slider *objects.Slider, point objects.TimingPoint, beatLength float64) (duration, hitSpacing float64, toHits bool) {
+	spans := float64(slider.RepeatCount)
+
+	// Drum roll distance including repeats
+	distance := slider.GetPixelLength() * spans * convertVelocityMultiplier
+
+	taikoVelocity := 100 * beatMap.Timings.SliderMult * convertVelocityMultiplier
+
+	duration = float64(int(distance / taikoVelocity * beatLength))
+
+	if beatMap.Mode != 0 {
+		return
+	}
+
+	osuVelocity := taikoVelocity * 1000 / beatLength
+
+	// osu!stable uses speed adjusted beat length for the stream only in beatmaps older than v8
+	if beatMap.Version >= 8 {
+		beatLength = point.GetBaseBeatLength()
+	}
+
+	hitSpacing = min(beatLength/beatMap.Timings.TickRate, duration/spans)
+
+	toHits = hitSpacing > 0 && distance/osuVelocity*1000 < 2*beatLength
+
+	return
+}
+
+// appendHitStream appends hits spaced evenly over the duration of the slider, hitsounds cycle through slider's edge hitsounds
+func appendHitStream(taikoObjects []*Object, slider *objects.Slider, obj *Object, duration, spacing float64) []*Object {
+	samples, _, _ := slider.GetEdgeSamples()
+
+	i := 0
+
+	for t := obj.StartTime; t <= obj.StartTime+duration+spacing/8; t += spacing {
+		sample := slider.GetBaseSample()
+		if len(samples) > 0 {
+			sample = samples[i]
+			i = (i + 1) % len(samples)
+		}
+
+		taikoObjects = append(taikoObjects, &Object{
+			ID:        int64(len(taikoObjects)),
+			Kind:      Hit,
+			StartTime: t,
+			EndTime:   t,
+			Kat:       sample&(sampleWhistle|sampleClap) > 0,
+			Strong:    sample&sampleFinish > 0,
+			Velocity:  obj.Velocity,
+		})
+	}
+
+	return taikoObjects
+}
@@ -0,0 +1,357 @@
+package taiko
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets"
+	"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/rplpa"
+	"log"
+	"math"
+)
+
+// strongHitWindow is the maximum time between both hits of a strong note
+const strongHitWindow = 30
+
+type ObjectState struct {
+	*Object
+
+	Result HitResult
+
+	StrongHit bool
+
+	// TicksHit is number of hit drum roll ticks or swell hits
+	TicksHit int
+
+	ticksJudged int
+	lastRim     int // -1 none, 0 centre, 1 rim
+}
+
+// IsDone returns true if object was fully judged
+func (state *ObjectState) IsDone() bool {
+	return state.Result != Ignore
+}
+
+type Judgement struct {
+	Time     int64
+	Object   *ObjectState
+	Result   HitResult
+	HitError float64
+	Tick     bool
+}
+
+type hitListener func(judgement Judgement, score *Score)
+
+type TaikoRuleSet struct {
+	beatMap *beatmap.BeatMap
+	diff    *difficulty.Difficulty
+
+	objects []*ObjectState
+	pending []*ObjectState
+
+	windows HitWindows
+
+	events     []keyEvent
+	eventIndex int
+	pressed    [actionCount]bool
+
+	// second hit of a strong note shouldn't hit the next object
+	skipAction Action
+	skipUntil  float64
+
+	score     *Score
+	hitErrors []float64
+
+	listener hitListener
+
+	lastTime int64
+	ended    bool
+}
+
+func NewTaikoRuleSet(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty, replay *rplpa.Replay) (*TaikoRuleSet, error) {
+	taikoObjects, err := ConvertObjects(beatMap, diff)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &TaikoRuleSet{
+		beatMap:   beatMap,
+		diff:      diff,
+		windows:   NewHitWindows(diff),
+		score:     newScore(diff),
+		lastTime:  math.MinInt64,
+		skipUntil: math.Inf(-1),
+	}
+
+	for _, o := range taikoObjects {
+		state := &ObjectState{Object: o, lastRim: -1}
+
+		set.objects = append(set.objects, state)
+		set.pending = append(set.pending, state)
+	}
+
+	if replay != nil {
+		set.events = buildKeyEvents(replay.ReplayData)
+	}
+
+	log.Println(fmt.Sprintf("osu!taiko ruleset: %d objects, %d key events", len(set.objects), len(set.events)))
+
+	return set, nil
+}
+
+func (set *TaikoRuleSet) Update(time int64) {
+	if time <= set.lastTime {
+		return
+	}
+
+	set.lastTime = time
+
+	for set.eventIndex < len(set.events) && set.events[set.eventIndex].time <= float64(time) {
+		event := set.events[set.eventIndex]
+
+		set.updatePassed(event.time)
+
+		if event.pressed {
+			set.hit(event.action, event.time)
+		}
+
+		set.pressed[event.action] = event.pressed
+
+		set.eventIndex++
+	}
+
+	set.updatePassed(float64(time))
+
+	if !set.ended && len(set.pending) == 0 {
+		set.printEndTable()
+
+		set.ended = true
+	}
+}
+
+func (set *TaikoRuleSet) hit(action Action, time float64) {
+	if action == set.skipAction && time <= set.skipUntil {
+		set.skipUntil = math.Inf(-1)
+		return
+	}
+
+	for _, state := range set.pending {
+		switch state.Kind {
+		case Swell:
+			if time < state.StartTime {
+				continue
+			}
+
+			rim := 0
+			if action.IsRim() {
+				rim = 1
+			}
+
+			// Swells require alternating between centre and rim
+			if state.lastRim != rim && state.TicksHit < state.RequiredHits {
+				state.lastRim = rim
+				state.TicksHit++
+
+				set.score.AddTick(true, false)
+				set.sendJudgement(Judgement{Time: int64(time), Object: state, Result: Great, Tick: true})
+
+				if state.TicksHit >= state.RequiredHits {
+					set.judge(state, time, Great, 0)
+				}
+			}
+
+			return
+		case DrumRoll:
+			if time < state.StartTime || time > state.EndTime {
+				continue
+			}
+
+			for state.ticksJudged < len(state.Ticks) {
+				tick := state.Ticks[state.ticksJudged]
+
+				if tick > time+set.tickLeniency(state) {
+					break
+				}
+
+				state.ticksJudged++
+
+				if time-tick <= set.tickLeniency(state) {
+					state.TicksHit++
+
+					set.score.AddTick(false, state.Strong)
+					set.sendJudgement(Judgement{Time: int64(time), Object: state, Result: Great, Tick: true})
+
+					break
+				}
+			}
+
+			return
+		default:
+			hitError := time - state.StartTime
+
+			result := set.windows.ResultFor(hitError)
+			if result == Ignore {
+				return
+			}
+
+			if action.IsRim() != state.Kat {
+				result = Miss
+			}
+
+			if result != Miss && state.Strong {
+				state.StrongHit = set.isStrongHit(action, time)
+			}
+
+			set.judge(state, time, result, hitError)
+			set.cleanPending()
+
+			return
+		}
+	}
+}
+
+// isStrongHit looks for the hit of the same colour with the other hand
+func (set *TaikoRuleSet) isStrongHit(action Action, time float64) bool {
+	other := map[Action]Action{
+		LeftCentre:  RightCentre,
+		RightCentre: LeftCentre,
+		LeftRim:     RightRim,
+		RightRim:    LeftRim,
+	}[action]
+
+	for i := set.eventIndex + 1; i < len(set.events); i++ {
+		event := set.events[i]
+
+		if event.time-time > strongHitWindow {
+			break
+		}
+
+		if event.pressed && event.action == other {
+			set.skipAction = other
+			set.skipUntil = event.time
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *TaikoRuleSet) tickLeniency(state *ObjectState) float64 {
+	if len(state.Ticks) < 2 {
+		return set.windows.Ok
+	}
+
+	return (state.Ticks[1] - state.Ticks[0]) / 2
+}
+
+func (set *TaikoRuleSet) updatePassed(time float64) {
+	for _, state := range set.pending {
+		switch state.Kind {
+		case Hit:
+			if time > state.StartTime+set.windows.Ok {
+				set.judge(state, state.StartTime+set.windows.Ok, Miss, 0)
+			}
+		case DrumRoll:
+			if time > state.EndTime {
+				state.ticksJudged = len(state.Ticks)
+				state.Result = Great // Drum rolls are not judged, only their ticks give score
+			}
+		case Swell:
+			if time > state.EndTime {
+				result := Miss
+
+				switch {
+				case state.TicksHit >= state.RequiredHits:
+					result = Great
+				case state.TicksHit >= state.RequiredHits/2:
+					result = Ok
+				}
+
+				set.judge(state, state.EndTime, result, 0)
+			}
+		}
+	}
+
+	set.cleanPending()
+}
+
+func (set *TaikoRuleSet) cleanPending() {
+	pending := set.pending[:0]
+
+	for _, state := range set.pending {
+		if !state.IsDone() {
+			pending = append(pending, state)
+		}
+	}
+
+	set.pending = pending
+}
+
+func (set *TaikoRuleSet) judge(state *ObjectState, time float64, result HitResult, hitError float64) {
+	state.Result = result
+
+	if state.Kind == Hit && result != Miss {
+		set.hitErrors = append(set.hitErrors, hitError)
+	}
+
+	set.score.AddResult(result, state.StrongHit)
+
+	set.sendJudgement(Judgement{
+		Time:     int64(time),
+		Object:   state,
+		Result:   result,
+		HitError: hitError,
+	})
+}
+
+func (set *TaikoRuleSet) sendJudgement(judgement Judgement) {
+	if set.listener != nil {
+		set.listener(judgement, set.score)
+	}
+}
+
+func (set *TaikoRuleSet) printEndTable() {
+	rulesets.LogEndTable([]string{"Score", "Accuracy", "Grade", "300", "100", "Miss", "Ticks", "Max Combo", "Mods", "UR"}, []string{
+		utils.Humanize(set.score.Score),
+		fmt.Sprintf("%.2f", set.score.Accuracy*100),
+		set.score.Grade.String(),
+		utils.Humanize(set.score.CountGreat),
+		utils.Humanize(set.score.CountOk),
+		utils.Humanize(set.score.CountMiss),
+		utils.Humanize(set.score.CountTicks),
+		utils.Humanize(set.score.Combo),
+		set.diff.GetModString(),
+		fmt.Sprintf("%.2f", set.GetUnstableRate()),
+	})
+}
+
+// GetUnstableRate returns unstable rate of hits, adjusted by rate changing mods
+func (set *TaikoRuleSet) GetUnstableRate() float64 {
+	return rulesets.UnstableRate(set.hitErrors, set.diff.GetSpeed())
+}
+
+func (set *TaikoRuleSet) SetListener(listener hitListener) {
+	set.listener = listener
+}
+
+func (set *TaikoRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+func (set *TaikoRuleSet) IsPressed(action Action) bool {
+	return set.pressed[action]
+}
+
+func (set *TaikoRuleSet) GetObjects() []*ObjectState {
+	return set.objects
+}
+
+func (set *TaikoRuleSet) GetScore() *Score {
+	return set.score
+}
+
+func (set *TaikoRuleSet) GetDifficulty() *difficulty.Difficulty {
+	return set.diff
+}
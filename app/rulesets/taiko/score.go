@@ -0,0 +1,138 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+)
+
+const (
+	tickValue      = 300
+	swellTickValue = 300
+)
+
+type Score struct {
+	Score        int64
+	Accuracy     float64
+	Grade        osu.Grade
+	CurrentCombo uint
+	Combo        uint
+	CountGreat   uint
+	CountOk      uint
+	CountMiss    uint
+	CountTicks   uint
+
+	judgements uint
+
+	modMultiplier float64
+	silver        bool
+}
+
+func newScore(diff *difficulty.Difficulty) *Score {
+	score := &Score{
+		Accuracy:      1,
+		modMultiplier: diff.GetScoreMultiplier(),
+		silver:        diff.CheckModActive(difficulty.Hidden | difficulty.Flashlight),
+	}
+
+	score.calculateGrade()
+
+	return score
+}
+
+// AddResult adds judgement of a hit or a swell. Score follows osu!stable's ScoreV1 formula, bonus grows every 10 combo up to 100 combo.
+func (s *Score) AddResult(result HitResult, strong bool) {
+	if result == Ignore {
+		return
+	}
+
+	switch result {
+	case Great:
+		s.CountGreat++
+	case Ok:
+		s.CountOk++
+	case Miss:
+		s.CountMiss++
+	}
+
+	s.judgements++
+
+	if result == Miss {
+		s.CurrentCombo = 0
+	} else {
+		value := int64(300)
+		if result == Ok {
+			value = 150
+		}
+
+		s.addScore(value, strong)
+
+		s.CurrentCombo++
+		s.Combo = max(s.Combo, s.CurrentCombo)
+	}
+
+	s.calculateAccuracy()
+	s.calculateGrade()
+}
+
+// AddTick adds drum roll tick or a swell hit, they don't affect accuracy
+func (s *Score) AddTick(swell, strong bool) {
+	s.CountTicks++
+
+	if swell {
+		s.Score += swellTickValue
+
+		return
+	}
+
+	s.addScore(tickValue, strong)
+}
+
+func (s *Score) addScore(value int64, strong bool) {
+	if strong {
+		value *= 2
+	}
+
+	bonus := min(s.CurrentCombo/10, 10)
+
+	s.Score += value + int64(float64(value)/10*float64(bonus)*s.modMultiplier)
+}
+
+func (s *Score) calculateAccuracy() {
+	if s.judgements == 0 {
+		s.Accuracy = 1
+		return
+	}
+
+	s.Accuracy = (float64(s.CountGreat) + float64(s.CountOk)/2) / float64(s.judgements)
+}
+
+func (s *Score) calculateGrade() {
+	ratio := 1.0
+	if s.judgements > 0 {
+		ratio = float64(s.CountGreat) / float64(s.judgements)
+	}
+
+	switch {
+	case s.CountGreat == s.judgements:
+		s.Grade = osu.SS
+	case ratio > 0.9 && s.CountMiss == 0:
+		s.Grade = osu.S
+	case ratio > 0.8 && s.CountMiss == 0 || ratio > 0.9:
+		s.Grade = osu.A
+	case ratio > 0.7 && s.CountMiss == 0 || ratio > 0.8:
+		s.Grade = osu.B
+	case ratio > 0.6:
+		s.Grade = osu.C
+	default:
+		s.Grade = osu.D
+	}
+
+	if s.silver {
+		switch s.Grade {
+		case osu.S:
+			s.Grade = osu.SH
+		case osu.SS:
+			s.Grade = osu.SSH
+		}
+	}
+}
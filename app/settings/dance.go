@@ -142,3 +142,23 @@ func (d *defaultsFactory) InitPippi() *pippi {
 		SpinnerRadius:    100,
 	}
 }
+
+type script struct {
+	Path string `file:"Select mover script" filter:"Lua script (*.lua)|lua" tooltip:"Lua script that moves the cursor. Relative paths start in danser's directory" liveedit:"false"`
+}
+
+func (d *defaultsFactory) InitScript() *script {
+	return &script{
+		Path: "movers/mover.lua",
+	}
+}
+
+type human struct {
+	Model string `file:"Select human model" filter:"JSON file (*.json)|json" tooltip:"Model built from replays with \"danser-cli human-model\". Relative paths start in danser's directory, built-in model is used if the file doesn't exist" liveedit:"false"`
+}
+
+func (d *defaultsFactory) InitHuman() *human {
+	return &human{
+		Model: "human.json",
+	}
+}
@@ -11,6 +11,7 @@ func initCursorDance() *cursorDance {
 			DefaultsFactory.InitSpinner(),
 		},
 		ComboTag:           false,
+		TAGAssignment:      "RoundRobin",
 		Battle:             false,
 		DoSpinnersTogether: true,
 		TAGSliderDance:     false,
@@ -39,12 +40,18 @@ func initCursorDance() *cursorDance {
 			Pippi: []*pippi{
 				DefaultsFactory.InitPippi(),
 			},
+			Script: []*script{
+				DefaultsFactory.InitScript(),
+			},
+			Human: []*human{
+				DefaultsFactory.InitHuman(),
+			},
 		},
 	}
 }
 
 type mover struct {
-	Mover             string `combo:"spline,bezier,circular,linear,axis,aggressive,flower,momentum,exgon,pippi"`
+	Mover             string `combo:"spline,bezier,circular,linear,axis,aggressive,flower,momentum,exgon,pippi,script,human"`
 	SliderDance       bool
 	RandomSliderDance bool
 }
@@ -76,7 +83,8 @@ type cursorDance struct {
 	Movers             []*mover   `new:"InitMover" wiki:"Help|https://github.com/Wieku/danser-go/wiki/Movers#available-movers"`
 	Spinners           []*spinner `new:"InitSpinner" wiki:"Help|https://github.com/Wieku/danser-go/wiki/Movers#available-spinner-movers"`
 	ComboTag           bool       `liveedit:"false"`
-	Battle             bool       `liveedit:"false"`
+	TAGAssignment      string     `label:"TAG object assignment" combo:"RoundRobin|Round robin,Travel|Minimize travel,Velocity|Minimize peak velocity" showif:"ComboTag=false" tooltip:"How objects are split between TAG cursors. Minimizing travel or peak velocity avoids cross-screen jumps, objects are assigned so that a cursor never has to hit an object while holding a slider" liveedit:"false"`
+	Battle             bool       `tooltip:"Every cursor dances the whole map and is judged, scores of movers are compared on the knockout overlay" liveedit:"false"`
 	DoSpinnersTogether bool       `liveedit:"false"`
 	TAGSliderDance     bool       `label:"TAG slider dance" liveedit:"false"`
 	MoverSettings      *moverSettings
@@ -91,4 +99,6 @@ type moverSettings struct {
 	ExGon      []*exgon    `new:"InitExGon"`
 	Linear     []*linear   `new:"InitLinear"`
 	Pippi      []*pippi    `new:"InitPippi"`
+	Script     []*script   `new:"InitScript"`
+	Human      []*human    `new:"InitHuman"`
 }
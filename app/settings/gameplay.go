@@ -187,6 +187,7 @@ func initGameplay() *gameplay {
 				XOffset: 0,
 				YOffset: 0,
 			},
+			Source:         "Online",
 			Mode:           "Normal",
 			ModsOnly:       false,
 			AlignRight:     false,
@@ -230,6 +231,20 @@ func initGameplay() *gameplay {
 			Path:       "",
 			AboveHpBar: false,
 		},
+		Mania: &mania{
+			ScrollTime:     600,
+			ColumnWidth:    70,
+			HitPosition:    920,
+			LaneOpacity:    0.8,
+			ShowJudgements: true,
+		},
+		Taiko: &taiko{
+			ScrollSpeed:    1.5,
+			LanePosition:   300,
+			LaneHeight:     160,
+			LaneOpacity:    0.8,
+			ShowJudgements: true,
+		},
 		Statistics:              make([]*Statistic, 0),
 		SBFont:                  "",
 		HUDFont:                 "",
@@ -237,10 +252,14 @@ func initGameplay() *gameplay {
 		ResultsScreenTime:       5,
 		FadeOutEnding:           true,
 		ResultsUseLocalTimeZone: false,
+		ResultsReplayLifeBar:    false,
 		ShowWarningArrows:       true,
 		ShowHitLighting:         false,
 		FlashlightDim:           1,
 		PlayUsername:            "Guest",
+		SavePlayReplays:         true,
+		SavePlayScores:          true,
+		SaveReplayScores:        false,
 		IgnoreFailsInReplays:    false,
 		PPVersion:               "latest",
 		LazerClassicScore:       false,
@@ -261,6 +280,8 @@ type gameplay struct {
 	Mods                    *mods
 	Boundaries              *boundaries
 	Underlay                *underlay
+	Mania                   *mania
+	Taiko                   *taiko
 	Statistics              []*Statistic `new:"InitStatistic" minSize:"0" wiki:"Help|https://github.com/Wieku/danser-go/wiki/Templates"`
 	SBFont                  string       `label:"Scoreboard / Ranking font" file:"Select SBR font" filter:"TrueType/OpenType Font (*.ttf, *.otf)|ttf,otf" tooltip:"Sets the font that will be used for score board names and ranking panel (use Aller Light to match osu!)" liveedit:"false"`
 	HUDFont                 string       `label:"Overlay (HUD) font" file:"Select HUD font" filter:"TrueType/OpenType Font (*.ttf, *.otf)|ttf,otf" tooltip:"Sets the font that will be used for PP/UR/hit counts" liveedit:"false"`
@@ -268,10 +289,14 @@ type gameplay struct {
 	ResultsScreenTime       float64      `label:"Results screen duration" min:"1" max:"20" format:"%.1fs" liveedit:"false"`
 	FadeOutEnding           bool    `label:"Fade out the ending of the results screen (does not affect audio)" liveedit:"false"`
 	ResultsUseLocalTimeZone bool         `label:"Show PC's time zone instead of UTC"`
+	ResultsReplayLifeBar    bool         `label:"Show replay's recorded HP graph in results" tooltip:"Draws life bar graph stored in the replay under danser's one"`
 	ShowWarningArrows       bool
 	ShowHitLighting         bool
 	FlashlightDim           float64
 	PlayUsername            string `liveedit:"false"`
+	SavePlayReplays         bool   `label:"Save replays of -play sessions" tooltip:"Finished plays will be saved to danser's replays directory" liveedit:"false"`
+	SavePlayScores          bool   `label:"Save scores of -play sessions" tooltip:"Results of finished plays will be stored in danser's database" liveedit:"false"`
+	SaveReplayScores        bool   `label:"Save scores of watched replays" tooltip:"Results of watched replays will be stored in danser's database" liveedit:"false"`
 	IgnoreFailsInReplays    bool
 	PPVersion               string `liveedit:"false" label:"PP counter version" combo:"211112|2021 pp rework (First Xexxar),220930|2022 pp rework,241007|2024 pp rework,latest|2025 Q1 update (latest)"`
 	LazerClassicScore       bool   `label:"Use \"Classic\" score for osu!lazer plays"`
@@ -377,6 +402,7 @@ type hitCounter struct {
 
 type scoreBoard struct {
 	*hudElementOffset
+	Source         string `combo:"Online|osu!api,Replays|Local replays,Scores|Local scores,All|Local replays and scores" tooltip:"Local replays are read from danser's replays directory, local scores from its database"`
 	Mode           string `combo:"Normal,Country,Friends" tooltip:"Country and Friends modes require osu!supporter and Authorization Code API Mode!"`
 	ModsOnly       bool   `label:"Show mod leaderboard"`
 	AlignRight     bool   `label:"Align to the right" label:"Simulates the second team of osu! multiplayer"`
@@ -425,6 +451,22 @@ type underlay struct {
 	AboveHpBar bool   `label:"Show underlay above HP bar" tooltip:"Use this if HP bar background is large"`
 }
 
+type mania struct {
+	ScrollTime     float64 `label:"Note scroll time" min:"100" max:"3000" format:"%.0fms" tooltip:"How long notes are visible before reaching the hit position"`
+	ColumnWidth    float64 `min:"20" max:"200" format:"%.0fpx"`
+	HitPosition    float64 `min:"400" max:"1080" format:"%.0fpx" tooltip:"Distance of the judgement line from the top of the screen (1080p)"`
+	LaneOpacity    float64 `scale:"100.0" format:"%.0f%%"`
+	ShowJudgements bool
+}
+
+type taiko struct {
+	ScrollSpeed    float64 `min:"0.5" max:"5" scale:"100" format:"%.0f%%" tooltip:"Multiplier of beatmap's scroll velocity"`
+	LanePosition   float64 `min:"100" max:"980" format:"%.0fpx" tooltip:"Vertical position of the lane centre (1080p)"`
+	LaneHeight     float64 `min:"60" max:"400" format:"%.0fpx"`
+	LaneOpacity    float64 `scale:"100.0" format:"%.0f%%"`
+	ShowJudgements bool
+}
+
 type Statistic struct {
 	Show bool
 
@@ -16,6 +16,7 @@ func initGeneral() *general {
 		OsuReplaysDir:     filepath.Join(osuBaseDir, "Replays"),
 		DiscordPresenceOn: true,
 		UnpackOszFiles:    true,
+		ImportOsuDatabase: true,
 		VerboseImportLogs: false,
 	}
 }
@@ -36,6 +37,9 @@ type general struct {
 	// Whether danser should unpack .osz files in Songs folder, osu! may complain about it
 	UnpackOszFiles bool
 
+	// Whether danser should seed its database with beatmaps from osu!.db located next to Songs directory, only new or changed files are parsed
+	ImportOsuDatabase bool `label:"Import from osu!.db"`
+
 	// Whether import details should be shown. If false, only failures will be logged.
 	VerboseImportLogs bool
 
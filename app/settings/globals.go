@@ -15,6 +15,7 @@ var SPEED = 1.0
 var PITCH = 1.0
 var TAG = 1
 var RECORD = false
+var HEADLESS = false
 var REPLAY = ""
 var LOCALOFFSET = 0
 var PerfGraph = false
@@ -10,6 +10,9 @@ func initKnockout() *knockout {
 		BubbleMinimumCombo:  200,
 		ExcludeMods:         "",
 		MaxPlayers:          50,
+		OsuScores:           false,
+		DateFrom:            "",
+		DateTo:              "",
 		MinPlayers:          1,
 		RevivePlayersAtEnd:  false,
 		LiveSort:            true,
@@ -41,6 +44,15 @@ type knockout struct {
 	// Max players shown (excluding danser) on a map. Caps at 50.
 	MaxPlayers int `skip:"true" label:"Max players loaded (legacy)" string:"true" min:"0" max:"100" tooltip:"Applicable only to classic knockout"`
 
+	// Whether local plays from osu!'s scores.db should be loaded too, their replays are read from osu!'s Data/r directory
+	OsuScores bool `label:"Load plays from osu!'s scores.db" tooltip:"Applicable only to classic knockout" liveedit:"false"`
+
+	// Plays older than this date (YYYY-MM-DD) are excluded, empty means no limit
+	DateFrom string `label:"Exclude plays before (YYYY-MM-DD)" tooltip:"Applicable only to classic knockout" liveedit:"false"`
+
+	// Plays newer than this date (YYYY-MM-DD) are excluded, empty means no limit
+	DateTo string `label:"Exclude plays after (YYYY-MM-DD)" tooltip:"Applicable only to classic knockout" liveedit:"false"`
+
 	// Min players shown on a map.
 	MinPlayers int `label:"Minimum alive players" string:"true" min:"0" max:"100" showif:"Mode=0,1,4"`
 
@@ -0,0 +1,292 @@
+package containers
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/shape"
+	"github.com/wieku/danser-go/framework/math/animation"
+	"github.com/wieku/danser-go/framework/math/animation/easing"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+const (
+	noteHeight     = 24.0
+	judgementFade  = 300.0
+	keyPressFade   = 100.0
+	receptorHeight = 80.0
+)
+
+var (
+	colorWhite  = color2.NewRGB(0.9, 0.9, 0.95)
+	colorBlue   = color2.NewRGB(0.35, 0.65, 1)
+	colorYellow = color2.NewRGB(1, 0.8, 0.2)
+)
+
+// ManiaPlayfield draws osu!mania lanes, notes and HUD of a single ruleset
+type ManiaPlayfield struct {
+	ruleset *mania.ManiaRuleSet
+
+	shapeRenderer *shape.Renderer
+	font          *font.Font
+
+	keyGliders []*animation.Glider
+	keyStates  []bool
+
+	judgement      mania.HitResult
+	judgementAlpha *animation.Glider
+	judgementScale *animation.Glider
+
+	time float64
+
+	width  float64
+	height float64
+}
+
+func NewManiaPlayfield(ruleset *mania.ManiaRuleSet, width, height float64) *ManiaPlayfield {
+	playfield := &ManiaPlayfield{
+		ruleset:        ruleset,
+		shapeRenderer:  shape.NewRenderer(),
+		font:           font.GetFont("Quicksand Bold"),
+		judgementAlpha: animation.NewGlider(0),
+		judgementScale: animation.NewGlider(1),
+		width:          width,
+		height:         height,
+	}
+
+	for i := 0; i < ruleset.GetKeys(); i++ {
+		playfield.keyGliders = append(playfield.keyGliders, animation.NewGlider(0))
+	}
+
+	playfield.keyStates = make([]bool, ruleset.GetKeys())
+
+	ruleset.SetListener(playfield.onJudgement)
+
+	return playfield
+}
+
+func (playfield *ManiaPlayfield) onJudgement(judgement mania.Judgement, _ *mania.Score) {
+	if !settings.Gameplay.Mania.ShowJudgements || judgement.Tail && judgement.Result != mania.Miss {
+		return
+	}
+
+	playfield.judgement = judgement.Result
+
+	playfield.judgementAlpha.Reset()
+	playfield.judgementAlpha.SetValue(1)
+	playfield.judgementAlpha.AddEventS(playfield.time+judgementFade/2, playfield.time+judgementFade, 1, 0)
+
+	playfield.judgementScale.Reset()
+	playfield.judgementScale.AddEventSEase(playfield.time, playfield.time+judgementFade/3, 1.3, 1, easing.OutQuad)
+}
+
+func (playfield *ManiaPlayfield) Update(time float64) {
+	playfield.time = time
+
+	playfield.ruleset.Update(int64(time))
+
+	for i, g := range playfield.keyGliders {
+		pressed := playfield.ruleset.IsPressed(i)
+
+		if pressed && !playfield.keyStates[i] {
+			g.Reset()
+			g.SetValue(1)
+		} else if !pressed && playfield.keyStates[i] {
+			g.AddEventS(time, time+keyPressFade, 1, 0)
+		}
+
+		playfield.keyStates[i] = pressed
+
+		g.Update(time)
+	}
+
+	playfield.judgementAlpha.Update(time)
+	playfield.judgementScale.Update(time)
+}
+
+func (playfield *ManiaPlayfield) IsEnded() bool {
+	return playfield.ruleset.IsEnded()
+}
+
+func (playfield *ManiaPlayfield) Draw(batch *batch.QuadBatch, camera mgl32.Mat4, alpha float64) {
+	keys := playfield.ruleset.GetKeys()
+
+	columnWidth := settings.Gameplay.Mania.ColumnWidth
+	hitPosition := settings.Gameplay.Mania.HitPosition
+	scrollTime := settings.Gameplay.Mania.ScrollTime
+
+	left := (playfield.width - columnWidth*float64(keys)) / 2
+
+	playfield.shapeRenderer.SetCamera(camera)
+	playfield.shapeRenderer.Begin()
+
+	playfield.shapeRenderer.SetColor(0, 0, 0, settings.Gameplay.Mania.LaneOpacity*alpha)
+	playfield.shapeRenderer.DrawQuad(
+		float32(left), 0,
+		float32(left+columnWidth*float64(keys)), 0,
+		float32(left+columnWidth*float64(keys)), float32(playfield.height),
+		float32(left), float32(playfield.height),
+	)
+
+	for i := 0; i < keys; i++ {
+		x := left + columnWidth*float64(i)
+
+		if press := playfield.keyGliders[i].GetValue(); press > 0.001 {
+			col := columnColor(i, keys)
+
+			playfield.shapeRenderer.SetColor(float64(col.R), float64(col.G), float64(col.B), 0.25*press*alpha)
+			playfield.shapeRenderer.DrawQuad(
+				float32(x), 0,
+				float32(x+columnWidth), 0,
+				float32(x+columnWidth), float32(hitPosition),
+				float32(x), float32(hitPosition),
+			)
+		}
+
+		playfield.shapeRenderer.SetColor(1, 1, 1, 0.15*alpha)
+		playfield.shapeRenderer.DrawLine(float32(x), 0, float32(x), float32(playfield.height), 1)
+	}
+
+	playfield.shapeRenderer.DrawLine(float32(left+columnWidth*float64(keys)), 0, float32(left+columnWidth*float64(keys)), float32(playfield.height), 1)
+
+	playfield.shapeRenderer.SetColor(1, 1, 1, 0.8*alpha)
+	playfield.shapeRenderer.DrawLine(float32(left), float32(hitPosition), float32(left+columnWidth*float64(keys)), float32(hitPosition), 2)
+
+	for _, state := range playfield.ruleset.GetNotes() {
+		if state.StartTime-playfield.time > scrollTime {
+			break
+		}
+
+		if state.IsDone() && !(state.Hold && state.TailResult == mania.Miss && state.EndTime > playfield.time) {
+			continue
+		}
+
+		x := left + columnWidth*float64(state.Column)
+
+		headY := hitPosition - (state.StartTime-playfield.time)/scrollTime*hitPosition
+		if state.Holding {
+			headY = hitPosition
+		}
+
+		col := columnColor(state.Column, keys)
+
+		noteAlpha := alpha
+		if state.HeadResult == mania.Miss || state.TailResult == mania.Miss {
+			noteAlpha *= 0.4
+		}
+
+		if state.Hold {
+			tailY := hitPosition - (state.EndTime-playfield.time)/scrollTime*hitPosition
+
+			playfield.shapeRenderer.SetColor(float64(col.R)*0.8, float64(col.G)*0.8, float64(col.B)*0.8, 0.8*noteAlpha)
+			playfield.shapeRenderer.DrawQuad(
+				float32(x+columnWidth*0.15), float32(tailY),
+				float32(x+columnWidth*0.85), float32(tailY),
+				float32(x+columnWidth*0.85), float32(headY),
+				float32(x+columnWidth*0.15), float32(headY),
+			)
+		}
+
+		if headY > playfield.height+noteHeight {
+			continue
+		}
+
+		playfield.shapeRenderer.SetColor(float64(col.R), float64(col.G), float64(col.B), noteAlpha)
+		playfield.shapeRenderer.DrawQuad(
+			float32(x+2), float32(headY-noteHeight),
+			float32(x+columnWidth-2), float32(headY-noteHeight),
+			float32(x+columnWidth-2), float32(headY),
+			float32(x+2), float32(headY),
+		)
+	}
+
+	for i := 0; i < keys; i++ {
+		x := left + columnWidth*float64(i)
+		col := columnColor(i, keys)
+
+		press := playfield.keyGliders[i].GetValue()
+
+		playfield.shapeRenderer.SetColor(float64(col.R), float64(col.G), float64(col.B), (0.3+0.7*press)*alpha)
+		playfield.shapeRenderer.DrawQuad(
+			float32(x+4), float32(hitPosition+8),
+			float32(x+columnWidth-4), float32(hitPosition+8),
+			float32(x+columnWidth-4), float32(hitPosition+8+receptorHeight),
+			float32(x+4), float32(hitPosition+8+receptorHeight),
+		)
+	}
+
+	playfield.shapeRenderer.End()
+
+	playfield.drawHUD(batch, camera, left, columnWidth*float64(keys), hitPosition, alpha)
+}
+
+func (playfield *ManiaPlayfield) drawHUD(batch *batch.QuadBatch, camera mgl32.Mat4, left, width, hitPosition, alpha float64) {
+	score := playfield.ruleset.GetScore()
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetCamera(camera)
+	batch.SetAdditive(false)
+
+	centre := left + width/2
+
+	if a := playfield.judgementAlpha.GetValue(); a > 0.001 {
+		r, g, b := judgementColor(playfield.judgement)
+
+		batch.SetColor(r, g, b, a*alpha)
+		playfield.font.DrawOrigin(batch, centre, hitPosition*0.6, vector.Centre, 40*playfield.judgementScale.GetValue(), false, playfield.judgement.String())
+	}
+
+	batch.SetColor(1, 1, 1, alpha)
+
+	if score.CurrentCombo > 0 {
+		playfield.font.DrawOrigin(batch, centre, hitPosition*0.4, vector.Centre, 56, true, utils.Humanize(score.CurrentCombo))
+	}
+
+	drawScore(batch, playfield.font, playfield.width, score.Score, score.Accuracy, score.Grade.String())
+
+	batch.SetColor(1, 1, 1, 1)
+	batch.End()
+}
+
+// drawScore draws score, accuracy and grade in the top right corner, batch has to be already started
+func drawScore(batch *batch.QuadBatch, fnt *font.Font, width float64, score int64, accuracy float64, grade string) {
+	fnt.DrawOrigin(batch, width-20, 10, vector.TopRight, 60, true, fmt.Sprintf("%08d", score))
+	fnt.DrawOrigin(batch, width-20, 80, vector.TopRight, 36, true, fmt.Sprintf("%.2f%%", accuracy*100))
+	fnt.DrawOrigin(batch, width-20, 125, vector.TopRight, 36, false, grade)
+}
+
+// columnColor mimics the default osu!mania skin, columns are coloured symmetrically from the edges with special middle column
+func columnColor(column, keys int) color2.Color {
+	if keys%2 == 1 && column == keys/2 {
+		return colorYellow
+	}
+
+	if min(column, keys-1-column)%2 == 0 {
+		return colorWhite
+	}
+
+	return colorBlue
+}
+
+func judgementColor(result mania.HitResult) (r, g, b float64) {
+	switch result {
+	case mania.HitMax:
+		return 0.7, 0.9, 1
+	case mania.Hit300:
+		return 1, 0.85, 0.3
+	case mania.Hit200:
+		return 0.4, 1, 0.4
+	case mania.Hit100:
+		return 0.3, 0.6, 1
+	case mania.Hit50:
+		return 0.7, 0.7, 0.7
+	default:
+		return 1, 0.2, 0.2
+	}
+}
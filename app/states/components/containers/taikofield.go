@@ -0,0 +1,290 @@
+package containers
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/shape"
+	"github.com/wieku/danser-go/framework/math/animation"
+	"github.com/wieku/danser-go/framework/math/animation/easing"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+const (
+	drumAreaWidth = 220.0
+	hitTargetX    = drumAreaWidth + 100
+)
+
+var (
+	colorDon      = color2.NewRGB(0.92, 0.27, 0.17)
+	colorKat      = color2.NewRGB(0.27, 0.6, 0.85)
+	colorDrumRoll = color2.NewRGB(0.99, 0.72, 0)
+	colorSwell    = color2.NewRGB(0.95, 0.45, 0.1)
+)
+
+// TaikoPlayfield draws osu!taiko lane, scrolling objects, drum input and HUD of a single ruleset
+type TaikoPlayfield struct {
+	ruleset *taiko.TaikoRuleSet
+
+	shapeRenderer *shape.Renderer
+	font          *font.Font
+
+	keyGliders [4]*animation.Glider
+	keyStates  [4]bool
+
+	judgement      taiko.HitResult
+	judgementAlpha *animation.Glider
+	judgementScale *animation.Glider
+
+	time float64
+
+	width  float64
+	height float64
+}
+
+func NewTaikoPlayfield(ruleset *taiko.TaikoRuleSet, width, height float64) *TaikoPlayfield {
+	playfield := &TaikoPlayfield{
+		ruleset:        ruleset,
+		shapeRenderer:  shape.NewRenderer(),
+		font:           font.GetFont("Quicksand Bold"),
+		judgementAlpha: animation.NewGlider(0),
+		judgementScale: animation.NewGlider(1),
+		width:          width,
+		height:         height,
+	}
+
+	for i := range playfield.keyGliders {
+		playfield.keyGliders[i] = animation.NewGlider(0)
+	}
+
+	ruleset.SetListener(playfield.onJudgement)
+
+	return playfield
+}
+
+func (playfield *TaikoPlayfield) onJudgement(judgement taiko.Judgement, _ *taiko.Score) {
+	if !settings.Gameplay.Taiko.ShowJudgements || judgement.Tick || judgement.Object.Kind == taiko.DrumRoll {
+		return
+	}
+
+	playfield.judgement = judgement.Result
+
+	playfield.judgementAlpha.Reset()
+	playfield.judgementAlpha.SetValue(1)
+	playfield.judgementAlpha.AddEventS(playfield.time+judgementFade/2, playfield.time+judgementFade, 1, 0)
+
+	playfield.judgementScale.Reset()
+	playfield.judgementScale.AddEventSEase(playfield.time, playfield.time+judgementFade/3, 1.3, 1, easing.OutQuad)
+}
+
+func (playfield *TaikoPlayfield) Update(time float64) {
+	playfield.time = time
+
+	playfield.ruleset.Update(int64(time))
+
+	for i, g := range playfield.keyGliders {
+		pressed := playfield.ruleset.IsPressed(taiko.Action(i))
+
+		if pressed && !playfield.keyStates[i] {
+			g.Reset()
+			g.SetValue(1)
+			g.AddEventS(time, time+keyPressFade*2, 1, 0)
+		}
+
+		playfield.keyStates[i] = pressed
+
+		g.Update(time)
+	}
+
+	playfield.judgementAlpha.Update(time)
+	playfield.judgementScale.Update(time)
+}
+
+func (playfield *TaikoPlayfield) IsEnded() bool {
+	return playfield.ruleset.IsEnded()
+}
+
+func (playfield *TaikoPlayfield) Draw(batch *batch.QuadBatch, camera mgl32.Mat4, alpha float64) {
+	laneY := settings.Gameplay.Taiko.LanePosition
+	laneHeight := settings.Gameplay.Taiko.LaneHeight
+
+	radius := laneHeight * 0.25
+
+	playfield.shapeRenderer.SetCamera(camera)
+	playfield.shapeRenderer.Begin()
+
+	playfield.shapeRenderer.SetColor(0, 0, 0, settings.Gameplay.Taiko.LaneOpacity*alpha)
+	playfield.drawRect(0, laneY-laneHeight/2, playfield.width, laneY+laneHeight/2)
+
+	playfield.shapeRenderer.SetColor(0.1, 0.1, 0.1, alpha)
+	playfield.drawRect(0, laneY-laneHeight/2, drumAreaWidth, laneY+laneHeight/2)
+
+	playfield.drawDrum(vector.NewVec2f(float32(drumAreaWidth/2), float32(laneY)), float32(laneHeight*0.4), alpha)
+
+	playfield.shapeRenderer.SetColor(1, 1, 1, 0.3*alpha)
+	playfield.shapeRenderer.DrawCircle(vector.NewVec2f(float32(hitTargetX), float32(laneY)), float32(radius*1.1))
+	playfield.shapeRenderer.SetColor(0, 0, 0, alpha)
+	playfield.shapeRenderer.DrawCircle(vector.NewVec2f(float32(hitTargetX), float32(laneY)), float32(radius*0.95))
+
+	taikoObjects := playfield.ruleset.GetObjects()
+
+	// Draw from the furthest object so the nearest ones are on top
+	for i := len(taikoObjects) - 1; i >= 0; i-- {
+		playfield.drawObject(taikoObjects[i], laneY, radius, alpha)
+	}
+
+	playfield.shapeRenderer.End()
+
+	playfield.drawHUD(batch, camera, laneY, laneHeight, alpha)
+}
+
+func (playfield *TaikoPlayfield) drawObject(state *taiko.ObjectState, laneY, radius, alpha float64) {
+	speed := state.Velocity * settings.Gameplay.Taiko.ScrollSpeed
+
+	x := hitTargetX + (state.StartTime-playfield.time)*speed
+	endX := hitTargetX + (state.EndTime-playfield.time)*speed
+
+	if x-radius*2 > playfield.width || endX+radius*2 < drumAreaWidth {
+		return
+	}
+
+	if state.Strong {
+		radius *= 1.5
+	}
+
+	objAlpha := alpha
+
+	switch state.Kind {
+	case taiko.Hit:
+		if state.IsDone() && state.Result != taiko.Miss {
+			return
+		}
+
+		if state.Result == taiko.Miss {
+			objAlpha *= 0.4
+		}
+
+		col := colorDon
+		if state.Kat {
+			col = colorKat
+		}
+
+		playfield.drawNote(x, laneY, radius, col, objAlpha)
+	case taiko.DrumRoll:
+		if state.IsDone() {
+			return
+		}
+
+		x = max(x, hitTargetX)
+
+		playfield.shapeRenderer.SetColor(float64(colorDrumRoll.R), float64(colorDrumRoll.G), float64(colorDrumRoll.B), objAlpha)
+		playfield.drawRect(x, laneY-radius*0.8, endX, laneY+radius*0.8)
+		playfield.shapeRenderer.DrawCircle(vector.NewVec2f(float32(endX), float32(laneY)), float32(radius*0.8))
+
+		playfield.drawNote(x, laneY, radius, colorDrumRoll, objAlpha)
+	case taiko.Swell:
+		if state.IsDone() {
+			return
+		}
+
+		if playfield.time >= state.StartTime {
+			x = hitTargetX
+		}
+
+		playfield.drawNote(x, laneY, radius*1.2, colorSwell, objAlpha)
+	}
+}
+
+func (playfield *TaikoPlayfield) drawNote(x, y, radius float64, col color2.Color, alpha float64) {
+	pos := vector.NewVec2f(float32(x), float32(y))
+
+	playfield.shapeRenderer.SetColor(1, 1, 1, alpha)
+	playfield.shapeRenderer.DrawCircle(pos, float32(radius))
+	playfield.shapeRenderer.SetColor(float64(col.R), float64(col.G), float64(col.B), alpha)
+	playfield.shapeRenderer.DrawCircle(pos, float32(radius*0.85))
+}
+
+// drawDrum draws the input drum, outer halves are rims and inner halves are centres
+func (playfield *TaikoPlayfield) drawDrum(pos vector.Vector2f, radius float32, alpha float64) {
+	halves := [4]struct {
+		action   taiko.Action
+		progress float32
+		radius   float32
+		col      color2.Color
+	}{
+		{taiko.LeftRim, -0.5, radius, colorKat},
+		{taiko.RightRim, 0.5, radius, colorKat},
+		{taiko.LeftCentre, -0.5, radius * 0.7, colorDon},
+		{taiko.RightCentre, 0.5, radius * 0.7, colorDon},
+	}
+
+	for _, h := range halves {
+		press := playfield.keyGliders[h.action].GetValue()
+
+		playfield.shapeRenderer.SetColor(0.25, 0.25, 0.25, alpha)
+		playfield.shapeRenderer.DrawCircleProgress(pos, h.radius, h.progress)
+
+		if press > 0.001 {
+			playfield.shapeRenderer.SetColor(float64(h.col.R), float64(h.col.G), float64(h.col.B), press*alpha)
+			playfield.shapeRenderer.DrawCircleProgress(pos, h.radius, h.progress)
+		}
+	}
+}
+
+func (playfield *TaikoPlayfield) drawRect(x1, y1, x2, y2 float64) {
+	playfield.shapeRenderer.DrawQuad(
+		float32(x1), float32(y1),
+		float32(x2), float32(y1),
+		float32(x2), float32(y2),
+		float32(x1), float32(y2),
+	)
+}
+
+func (playfield *TaikoPlayfield) drawHUD(batch *batch.QuadBatch, camera mgl32.Mat4, laneY, laneHeight, alpha float64) {
+	score := playfield.ruleset.GetScore()
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetCamera(camera)
+	batch.SetAdditive(false)
+
+	if a := playfield.judgementAlpha.GetValue(); a > 0.001 {
+		r, g, b := taikoJudgementColor(playfield.judgement)
+
+		batch.SetColor(r, g, b, a*alpha)
+		playfield.font.DrawOrigin(batch, hitTargetX, laneY-laneHeight/2-30, vector.Centre, 40*playfield.judgementScale.GetValue(), false, playfield.judgement.String())
+	}
+
+	batch.SetColor(1, 1, 1, alpha)
+
+	for _, state := range playfield.ruleset.GetObjects() {
+		if state.Kind == taiko.Swell && !state.IsDone() && playfield.time >= state.StartTime && playfield.time <= state.EndTime {
+			playfield.font.DrawOrigin(batch, hitTargetX, laneY, vector.Centre, 36, true, fmt.Sprintf("%d", state.RequiredHits-state.TicksHit))
+		}
+	}
+
+	if score.CurrentCombo > 0 {
+		playfield.font.DrawOrigin(batch, drumAreaWidth/2, laneY, vector.Centre, 32, true, utils.Humanize(score.CurrentCombo))
+	}
+
+	drawScore(batch, playfield.font, playfield.width, score.Score, score.Accuracy, score.Grade.String())
+
+	batch.SetColor(1, 1, 1, 1)
+	batch.End()
+}
+
+func taikoJudgementColor(result taiko.HitResult) (r, g, b float64) {
+	switch result {
+	case taiko.Great:
+		return 1, 0.85, 0.3
+	case taiko.Ok:
+		return 0.4, 1, 0.4
+	default:
+		return 1, 0.2, 0.2
+	}
+}
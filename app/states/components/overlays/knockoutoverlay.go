@@ -65,6 +65,16 @@ type knockoutPlayer struct {
 	currentIndex int
 }
 
+// battleResult holds final standing of a cursor in cursordance battle mode
+type battleResult struct {
+	name     string
+	index    int
+	score    int64
+	accuracy float64
+	combo    uint
+	pp       float64
+}
+
 type bubble struct {
 	deathFade  *animation.Glider
 	deathSlide *animation.Glider
@@ -110,7 +120,7 @@ func newBubble(position vector.Vector2f, time float64, name string, combo int64,
 }
 
 type KnockoutOverlay struct {
-	controller   *dance.ReplayController
+	controller   dance.ScoredController
 	font         *font.Font
 	players      map[string]*knockoutPlayer
 	playersArray []*knockoutPlayer
@@ -135,11 +145,16 @@ type KnockoutOverlay struct {
 	fade      *animation.Glider
 
 	alivePlayers int
+
+	// In cursordance battle mode, final standings are shown at the end of the map
+	battle      bool
+	results     []battleResult
+	resultsFade *animation.Glider
 }
 
-func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverlay {
+func NewKnockoutOverlay(controller dance.ScoredController) *KnockoutOverlay {
 	overlay := new(KnockoutOverlay)
-	overlay.controller = replayController
+	overlay.controller = controller
 
 	if font.GetFont("Quicksand Bold") == nil {
 		file, _ := assets.Open("assets/fonts/Quicksand-Bold.ttf")
@@ -153,17 +168,20 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 	overlay.playersArray = make([]*knockoutPlayer, 0)
 	overlay.deathBubbles = make([]*bubble, 0)
 	overlay.names = make(map[*graphics.Cursor]string)
-	overlay.generator = rand.New(rand.NewSource(replayController.GetBeatMap().TimeAdded))
+	overlay.generator = rand.New(rand.NewSource(controller.GetBeatMap().TimeAdded))
 
 	overlay.ScaledHeight = 1080.0
 	overlay.ScaledWidth = overlay.ScaledHeight * settings.Graphics.GetAspectRatio()
 
 	overlay.fade = animation.NewGlider(1)
 
-	for i, r := range replayController.GetReplays() {
-		cursor := replayController.GetCursors()[i]
+	_, overlay.battle = controller.(*dance.BattleController)
+	overlay.resultsFade = animation.NewGlider(0)
+
+	for i, r := range controller.GetReplays() {
+		cursor := controller.GetCursors()[i]
 		overlay.names[cursor] = r.Name
-		overlay.players[r.Name] = &knockoutPlayer{animation.NewGlider(1), animation.NewGlider(0), animation.NewGlider(overlay.ScaledHeight * 0.9 * 1.04 / (51)), animation.NewGlider(float64(i)), animation.NewTargetGlider(0, 0), animation.NewTargetGlider(0, 2), animation.NewTargetGlider(100, 2), 0, 0, r.MaxCombo, false, 0, 0.0, 0, make([]stats, len(replayController.GetBeatMap().HitObjects)), 0.0, osu.Hit300, animation.NewGlider(0), animation.NewGlider(0), r.Name, i, i}
+		overlay.players[r.Name] = &knockoutPlayer{animation.NewGlider(1), animation.NewGlider(0), animation.NewGlider(overlay.ScaledHeight * 0.9 * 1.04 / (51)), animation.NewGlider(float64(i)), animation.NewTargetGlider(0, 0), animation.NewTargetGlider(0, 2), animation.NewTargetGlider(100, 2), 0, 0, r.MaxCombo, false, 0, 0.0, 0, make([]stats, len(controller.GetBeatMap().HitObjects)), 0.0, osu.Hit300, animation.NewGlider(0), animation.NewGlider(0), r.Name, i, i}
 		overlay.players[r.Name].index.SetEasing(easing.InOutQuad)
 		overlay.playersArray = append(overlay.playersArray, overlay.players[r.Name])
 
@@ -186,7 +204,7 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 		}
 	}
 
-	replayController.GetRuleset().SetListener(overlay.hitReceived)
+	controller.GetRuleset().SetListener(overlay.hitReceived)
 
 	sortFunc := func(number int64, instantSort bool) {
 		alive := 0
@@ -231,8 +249,12 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 		discord.UpdateKnockout(alive, len(overlay.playersArray))
 	}
 
-	replayController.GetRuleset().SetEndListener(func(time int64, number int64) {
-		if number == int64(len(replayController.GetBeatMap().HitObjects)-1) && settings.Knockout.RevivePlayersAtEnd {
+	controller.GetRuleset().SetEndListener(func(time int64, number int64) {
+		if overlay.battle && number == int64(len(controller.GetBeatMap().HitObjects)-1) {
+			overlay.showResults()
+		}
+
+		if number == int64(len(controller.GetBeatMap().HitObjects)-1) && settings.Knockout.RevivePlayersAtEnd {
 			for _, player := range overlay.players {
 				player.hasBroken = false
 				player.breakTime = 0
@@ -353,6 +375,7 @@ func (overlay *KnockoutOverlay) Update(time float64) {
 
 	overlay.updateBreaks(overlay.normalTime)
 	overlay.fade.Update(overlay.normalTime)
+	overlay.resultsFade.Update(overlay.normalTime)
 
 	for _, r := range overlay.controller.GetReplays() {
 		player := overlay.players[r.Name]
@@ -459,6 +482,8 @@ func (overlay *KnockoutOverlay) DrawNormal(batch *batch.QuadBatch, colors []colo
 }
 
 func (overlay *KnockoutOverlay) DrawHUD(batch *batch.QuadBatch, colors []color2.Color, alpha float64) {
+	defer overlay.drawResults(batch, colors, alpha)
+
 	alpha *= overlay.fade.GetValue()
 
 	batch.ResetTransform()
@@ -635,6 +660,125 @@ func (overlay *KnockoutOverlay) DrawHUD(batch *batch.QuadBatch, colors []color2.
 	}
 }
 
+// showResults collects final scores of cursors sorted by score and fades in the results panel
+func (overlay *KnockoutOverlay) showResults() {
+	ruleset := overlay.controller.GetRuleset()
+
+	overlay.results = overlay.results[:0]
+
+	for i, r := range overlay.controller.GetReplays() {
+		score := ruleset.GetScore(overlay.controller.GetCursors()[i])
+
+		overlay.results = append(overlay.results, battleResult{
+			name:     r.Name,
+			index:    i,
+			score:    score.Score,
+			accuracy: score.Accuracy,
+			combo:    score.Combo,
+			pp:       score.PP.Total,
+		})
+	}
+
+	sort.SliceStable(overlay.results, func(i, j int) bool {
+		return overlay.results[i].score > overlay.results[j].score
+	})
+
+	overlay.resultsFade.AddEventEase(overlay.normalTime, overlay.normalTime+500, 1, easing.OutQuad)
+}
+
+// drawResults draws a table with score, accuracy, max combo and pp of every cursor in the middle of the screen
+func (overlay *KnockoutOverlay) drawResults(batch *batch.QuadBatch, colors []color2.Color, alpha float64) {
+	alpha *= overlay.resultsFade.GetValue()
+
+	if alpha < 0.001 || len(overlay.results) == 0 {
+		return
+	}
+
+	batch.ResetTransform()
+
+	rowHeight := min(overlay.ScaledHeight/25, overlay.ScaledHeight*0.8/float64(len(overlay.results)+1))
+	scl := rowHeight / 1.2
+
+	header := []string{"#", "Mover", "Score", "Accuracy", "Combo", "PP"}
+
+	rows := make([][]string, 0, len(overlay.results))
+
+	for i, r := range overlay.results {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			r.name,
+			utils.Humanize(r.score),
+			fmt.Sprintf("%.2f%%", r.accuracy*100),
+			fmt.Sprintf("%dx", r.combo),
+			fmt.Sprintf("%.2fpp", r.pp),
+		})
+	}
+
+	widths := make([]float64, len(header))
+
+	for i, h := range header {
+		widths[i] = overlay.font.GetWidth(scl, h)
+
+		for _, row := range rows {
+			if i == 1 {
+				widths[i] = max(widths[i], overlay.font.GetWidth(scl, row[i]))
+			} else {
+				widths[i] = max(widths[i], overlay.font.GetWidthMonospaced(scl, row[i]))
+			}
+		}
+	}
+
+	width := 2 * scl
+	for _, w := range widths {
+		width += w + scl
+	}
+
+	width -= scl
+
+	height := float64(len(rows)+1)*rowHeight + scl
+
+	x := (overlay.ScaledWidth - width) / 2
+	y := (overlay.ScaledHeight - height) / 2
+
+	batch.SetColor(0, 0, 0, alpha*0.8)
+	batch.SetSubScale(width/2, height/2)
+	batch.SetTranslation(vector.NewVec2d(overlay.ScaledWidth/2, overlay.ScaledHeight/2))
+	batch.DrawUnit(graphics.Pixel.GetRegion())
+
+	batch.ResetTransform()
+
+	drawRow := func(rowY float64, cells []string, monospaced bool, nameColor color2.Color) {
+		cellX := x + scl
+
+		for i, cell := range cells {
+			batch.SetColor(1, 1, 1, alpha)
+
+			if i == 1 {
+				batch.SetColor(float64(nameColor.R), float64(nameColor.G), float64(nameColor.B), alpha)
+			}
+
+			// Rank and name are aligned left, numbers right
+			if i < 2 {
+				overlay.font.DrawOrigin(batch, cellX, rowY, vector.CentreLeft, scl, monospaced && i == 0, cell)
+			} else {
+				overlay.font.DrawOrigin(batch, cellX+widths[i], rowY, vector.CentreRight, scl, monospaced, cell)
+			}
+
+			cellX += widths[i] + scl
+		}
+	}
+
+	rowY := y + scl/2 + rowHeight/2
+
+	drawRow(rowY, header, false, color2.NewL(1))
+
+	for i, r := range overlay.results {
+		rowY += rowHeight
+
+		drawRow(rowY, rows[i], true, colors[r.index])
+	}
+}
+
 func (overlay *KnockoutOverlay) IsBroken(cursor *graphics.Cursor) bool {
 	return overlay.players[overlay.names[cursor]].hasBroken
 }
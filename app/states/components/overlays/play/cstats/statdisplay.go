@@ -88,6 +88,10 @@ func (stat *Stat) compile() bool {
 
 			stat.usedStats = append(stat.usedStats, extracted)
 
+			if strings.HasPrefix(extracted, "pp_") || strings.HasPrefix(extracted, "stars_") || strings.HasPrefix(extracted, "cStars_") {
+				stat.display.versionStats = true
+			}
+
 			if strings.Contains(extracted, "Roll") {
 				spl := strings.Split(extracted, "Roll")
 
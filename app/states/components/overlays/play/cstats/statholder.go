@@ -4,6 +4,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap"
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance"
 	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 )
 
@@ -30,6 +31,8 @@ func NewStatHolder(bMap *beatmap.BeatMap, diff *difficulty.Difficulty) *StatHold
 	holder.SetScoreStats(osu.Score{})
 	holder.SetFCPP(api.PPv2Results{})
 	holder.SetSSPP(api.PPv2Results{})
+	holder.SetVersionStars(make([]api.Attributes, len(performance.Versions)))
+	holder.SetVersionStats(make([]performance.Comparison, len(performance.Versions)))
 	holder.SetUsername("")
 	holder.SetHP(1)
 
@@ -146,6 +149,22 @@ func (h *StatHolder) SetSSPP(pp api.PPv2Results) {
 	h.stats["ssPPFL"] = pp.Flashlight
 }
 
+// SetVersionStars sets star ratings calculated with all bundled pp versions, order has to match performance.Versions
+func (h *StatHolder) SetVersionStars(attribs []api.Attributes) {
+	for i, a := range attribs {
+		h.stats["stars_"+performance.Versions[i].Name] = a.Total
+	}
+}
+
+func (h *StatHolder) SetVersionStats(results []performance.Comparison) {
+	for i, r := range results {
+		name := performance.Versions[i].Name
+
+		h.stats["cStars_"+name] = r.Stars.Total
+		h.stats["pp_"+name] = r.PP.Total
+	}
+}
+
 func (h *StatHolder) AddClick(time float64) {
 	h.clickTimes = append(h.clickTimes, time)
 }
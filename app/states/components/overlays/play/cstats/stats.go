@@ -22,6 +22,8 @@ type StatDisplay struct {
 	counterMap map[string]*rollingCounter
 	lastTime   int64
 
+	versionStats bool
+
 	mtx *sync.RWMutex // To not risk a crash during settings reload
 }
 
@@ -55,6 +57,7 @@ func (statDisplay *StatDisplay) loadDisplays() {
 	statDisplay.mtx.Lock()
 
 	statDisplay.stats = make([]*Stat, 0)
+	statDisplay.versionStats = false
 
 	for i, c := range settings.Gameplay.Statistics {
 		stat := NewStat(statDisplay, c, statDisplay.engine.New(strconv.Itoa(i)))
@@ -67,6 +70,11 @@ func (statDisplay *StatDisplay) loadDisplays() {
 	statDisplay.mtx.Unlock()
 }
 
+// UsesVersionStats returns true if any template uses values calculated with all bundled pp versions
+func (statDisplay *StatDisplay) UsesVersionStats() bool {
+	return statDisplay.versionStats
+}
+
 func (statDisplay *StatDisplay) GetStatHolder() *StatHolder {
 	return statDisplay.statHolder
 }
@@ -0,0 +1,146 @@
+package play
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/app/osuapi"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type localScore struct {
+	score  osuapi.Score
+	mods   difficulty.Modifier
+	replay string
+}
+
+// getLocalScores builds leaderboard scores from replays in danser's replays/{md5} directory and/or local scores table.
+// Only the best score of each player is kept, omitReplay excludes the currently watched replay.
+func getLocalScores(beatMap *beatmap.BeatMap, source, omitReplay string) (scores []osuapi.Score) {
+	var candidates []localScore
+
+	replays := make(map[string]struct{})
+
+	if source == "Replays" || source == "All" {
+		for _, s := range getReplayScores(beatMap) {
+			replays[cleanPath(s.replay)] = struct{}{}
+			candidates = append(candidates, s)
+		}
+	}
+
+	if source == "Scores" || source == "All" {
+		dbScores, err := database.GetScores(beatMap.MD5)
+		if err != nil {
+			log.Println("Failed to load local scores:", err)
+		}
+
+		for _, s := range dbScores {
+			if _, ok := replays[cleanPath(s.Replay)]; ok && s.Replay != "" { // Already loaded from the replay itself
+				continue
+			}
+
+			candidates = append(candidates, localScore{
+				score:  newLocalScore(s.Player, s.Score, s.MaxCombo, s.Accuracy),
+				mods:   difficulty.Modifier(s.LegacyMods),
+				replay: s.Replay,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score.Score > candidates[j].score.Score
+	})
+
+	players := make(map[string]struct{})
+
+	for _, c := range candidates {
+		if omitReplay != "" && c.replay != "" && cleanPath(c.replay) == cleanPath(omitReplay) {
+			continue
+		}
+
+		if settings.Gameplay.ScoreBoard.ModsOnly && c.mods.Legacy() != beatMap.Diff.Mods.Legacy() {
+			continue
+		}
+
+		if _, ok := players[c.score.User.Username]; ok {
+			continue
+		}
+
+		players[c.score.User.Username] = struct{}{}
+
+		scores = append(scores, c.score)
+	}
+
+	return
+}
+
+// getReplayScores reads scores from headers of replays stored in classic knockout directory
+func getReplayScores(beatMap *beatmap.BeatMap) (scores []localScore) {
+	replayPaths, _ := files.SearchFiles(filepath.Join(env.DataDir(), "replays", beatMap.MD5), "*.osr", 0)
+
+	for _, path := range replayPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Println("Failed to read replay:", err)
+			continue
+		}
+
+		replay, err := rplpa.ParseReplay(data)
+		if err != nil {
+			log.Println("Failed to parse replay:", err)
+			continue
+		}
+
+		if !strings.EqualFold(replay.BeatmapMD5, beatMap.MD5) || replay.PlayMode != 0 {
+			continue
+		}
+
+		scores = append(scores, localScore{
+			score:  newLocalScore(replay.Username, int64(replay.Score), int64(replay.MaxCombo), replayAccuracy(replay)),
+			mods:   difficulty.Modifier(replay.Mods),
+			replay: path,
+		})
+	}
+
+	return
+}
+
+// replayAccuracy calculates osu!standard accuracy from hit counts stored in replay's header
+func replayAccuracy(replay *rplpa.Replay) float64 {
+	hits := int64(replay.Count300) + int64(replay.Count100) + int64(replay.Count50) + int64(replay.CountMiss)
+	if hits == 0 {
+		return 0
+	}
+
+	return float64(300*int64(replay.Count300)+100*int64(replay.Count100)+50*int64(replay.Count50)) / float64(300*hits)
+}
+
+func cleanPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+
+	return filepath.Clean(path)
+}
+
+func newLocalScore(player string, score, combo int64, accuracy float64) osuapi.Score {
+	return osuapi.Score{
+		Score:             score,
+		TotalScore:        score,
+		ClassicTotalScore: score,
+		LegacyTotalScore:  score,
+		MaxCombo:          combo,
+		Accuracy:          accuracy,
+		User: osuapi.User{
+			Username: player,
+		},
+	}
+}
@@ -58,6 +58,7 @@ type RankingPanel struct {
 	hpSections     []vector.Vector2d
 	shapeRenderer  *shape.Renderer
 	hpGraph        []vector.Vector2d
+	replayHpGraph  []vector.Vector2d
 	stats          []string
 	perfect        *sprite.Sprite
 }
@@ -189,10 +190,19 @@ func NewRankingPanel(cursor *graphics.Cursor, ruleset *osu.OsuRuleSet, hitError
 	panel.hpGraph = make([]vector.Vector2d, len(hpGraph))
 	copy(panel.hpGraph, hpGraph)
 
-	for len(panel.hpGraph) > 100 {
-		for i := len(panel.hpGraph) - 1; i >= 0; i -= 2 {
-			panel.hpGraph = append(panel.hpGraph[:i], panel.hpGraph[i+1:]...)
+	panel.hpGraph = thinGraph(panel.hpGraph)
+
+	if settings.Gameplay.ResultsReplayLifeBar && len(panel.hpGraph) > 1 {
+		begin := panel.hpGraph[0].X
+		end := panel.hpGraph[len(panel.hpGraph)-1].X
+
+		for _, p := range cursor.LifeBar {
+			if p.X >= begin && p.X <= end {
+				panel.replayHpGraph = append(panel.replayHpGraph, p)
+			}
 		}
+
+		panel.replayHpGraph = thinGraph(panel.replayHpGraph)
 	}
 
 	if score.PerfectCombo {
@@ -227,6 +237,17 @@ func NewRankingPanel(cursor *graphics.Cursor, ruleset *osu.OsuRuleSet, hitError
 	return panel
 }
 
+// thinGraph removes every second point until there are at most 100 left
+func thinGraph(graph []vector.Vector2d) []vector.Vector2d {
+	for len(graph) > 100 {
+		for i := len(graph) - 1; i >= 0; i -= 2 {
+			graph = append(graph[:i], graph[i+1:]...)
+		}
+	}
+
+	return graph
+}
+
 func (panel *RankingPanel) loadMods() {
 	mods := panel.ruleset.GetBeatMap().Diff.Mods.StringFull()
 
@@ -314,6 +335,21 @@ func (panel *RankingPanel) Draw(batch *batch.QuadBatch, alpha float64) {
 	begin := panel.hpGraph[0].X
 	end := panel.hpGraph[len(panel.hpGraph)-1].X
 
+	// Life bar recorded in the replay is drawn below danser's one, so drifts are easy to spot
+	panel.shapeRenderer.SetColor(1, 1, 1, alpha*0.6)
+
+	for i := 0; i < len(panel.replayHpGraph)-1; i++ {
+		p1 := panel.replayHpGraph[i]
+		p2 := panel.replayHpGraph[i+1]
+
+		p1X := 256 + 8 + 298*(p1.X-begin)/(end-begin)
+		p1Y := 608 + 8 + 137.6*(1-p1.Y)
+		p2X := 256 + 8 + 298*(p2.X-begin)/(end-begin)
+		p2Y := 608 + 8 + 137.6*(1-p2.Y)
+
+		panel.shapeRenderer.DrawLine(float32(p1X), float32(p1Y), float32(p2X), float32(p2Y), 2)
+	}
+
 	for i := 0; i < len(panel.hpGraph)-1; i++ {
 		p1 := panel.hpGraph[i]
 		p2 := panel.hpGraph[i+1]
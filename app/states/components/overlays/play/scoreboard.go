@@ -43,7 +43,7 @@ type ScoreBoard struct {
 	lazerScore bool
 }
 
-func NewScoreboard(beatMap *beatmap.BeatMap, lazerScore bool, omitID int64) *ScoreBoard {
+func NewScoreboard(beatMap *beatmap.BeatMap, lazerScore bool, omitID int64, omitReplay string) *ScoreBoard {
 	board := &ScoreBoard{
 		first:            true,
 		explosionManager: sprite.NewManager(),
@@ -65,6 +65,25 @@ func NewScoreboard(beatMap *beatmap.BeatMap, lazerScore bool, omitID int64) *Sco
 		}
 	}
 
+	if source := settings.Gameplay.ScoreBoard.Source; source != "" && source != "Online" {
+		scores := getLocalScores(beatMap, source, omitReplay)
+
+		if len(scores) == 0 {
+			log.Println("Can't find local scores!")
+		}
+
+		for i := 0; i < min(len(scores), 50); i++ {
+			entry := NewScoreboardEntry(scores[i].User.Username, scores[i], lazerScore, i+1, false)
+
+			board.scores = append(board.scores, entry)
+			board.displayScores = append(board.displayScores, entry)
+		}
+
+		log.Println("LOCAL SCORES", len(board.scores))
+
+		return board
+	}
+
 	var mods []string
 
 	mode := osuapi.NormalMode
@@ -8,6 +8,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	camera2 "github.com/wieku/danser-go/app/bmath/camera"
+	"github.com/wieku/danser-go/app/database"
 	"github.com/wieku/danser-go/app/discord"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/input"
@@ -130,6 +131,10 @@ type ScoreOverlay struct {
 
 	customStats *cstats.StatDisplay
 
+	versionStatsReady bool
+
+	scoreSaved bool
+
 	lazerScore bool
 
 	skipped bool
@@ -330,7 +335,7 @@ func NewScoreOverlay(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *ScoreOve
 		overlay.flashlight = common.NewFlashlight(overlay.ruleset.GetBeatMap())
 	}
 
-	overlay.entry = play.NewScoreboard(overlay.ruleset.GetBeatMap(), ruleset.GetPlayerDifficulty(overlay.cursor).CheckModActive(difficulty.Lazer), overlay.cursor.ScoreID)
+	overlay.entry = play.NewScoreboard(overlay.ruleset.GetBeatMap(), ruleset.GetPlayerDifficulty(overlay.cursor).CheckModActive(difficulty.Lazer), overlay.cursor.ScoreID, overlay.cursor.ReplayPath)
 	overlay.entry.AddPlayer(overlay.cursor.Name, overlay.cursor.IsAutoplay)
 
 	overlay.initArrows()
@@ -345,6 +350,8 @@ func NewScoreOverlay(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *ScoreOve
 	overlay.customStats.GetStatHolder().SetStars(endStars)
 	overlay.customStats.GetStatHolder().SetCurrentStars(currentStars)
 
+	overlay.updateVersionStats()
+
 	return overlay
 }
 
@@ -468,6 +475,24 @@ func (overlay *ScoreOverlay) hitReceived(c *graphics.Cursor, judgementResult osu
 
 	currentStars := overlay.ruleset.GetCurrentDiffAttribs(overlay.cursor)
 	overlay.customStats.GetStatHolder().SetCurrentStars(currentStars)
+
+	overlay.updateVersionStats()
+}
+
+// updateVersionStats feeds custom stats with values from all bundled pp versions, they are calculated only if templates use them
+func (overlay *ScoreOverlay) updateVersionStats() {
+	if !overlay.customStats.UsesVersionStats() {
+		return
+	}
+
+	if !overlay.versionStatsReady {
+		overlay.ruleset.PrepareVersionComparison(overlay.cursor)
+		overlay.customStats.GetStatHolder().SetVersionStars(overlay.ruleset.GetFinalVersionDiffAttribs(overlay.cursor))
+
+		overlay.versionStatsReady = true
+	}
+
+	overlay.customStats.GetStatHolder().SetVersionStats(overlay.ruleset.GetVersionComparison(overlay.cursor))
 }
 
 func (overlay *ScoreOverlay) clickReceived(c *graphics.Cursor, leftMouse, rightMouse, leftKb, rightKb, smoke osu.ButtonAction) {
@@ -567,6 +592,66 @@ func (overlay *ScoreOverlay) Update(time float64) {
 	overlay.customStats.GetStatHolder().SetHP(overlay.ruleset.GetHP(overlay.cursor))
 	overlay.customStats.GetStatHolder().SetUsername(overlay.cursor.Name)
 	overlay.customStats.Update(overlay.audioTime, overlay.normalTime)
+
+	if !overlay.scoreSaved && overlay.ruleset.IsEnded() {
+		overlay.scoreSaved = true
+		overlay.saveScore()
+	}
+}
+
+// saveScore stores the result of a finished play or replay in danser's database
+func (overlay *ScoreOverlay) saveScore() {
+	var source database.ScoreSource
+
+	switch {
+	case settings.PLAY && settings.Gameplay.SavePlayScores:
+		source = database.SourcePlay
+	case overlay.cursor.IsReplay && settings.Gameplay.SaveReplayScores:
+		source = database.SourceReplay
+	default:
+		return
+	}
+
+	if overlay.ruleset.IsFailed(overlay.cursor) {
+		return
+	}
+
+	score := overlay.ruleset.GetScore(overlay.cursor)
+	diff := overlay.ruleset.GetPlayerDifficulty(overlay.cursor)
+
+	dbScore := &database.Score{
+		BeatmapMD5:   overlay.ruleset.GetBeatMap().MD5,
+		Player:       overlay.cursor.Name,
+		Source:       source,
+		Mods:         diff.GetModString(),
+		LegacyMods:   int64(diff.Mods.Legacy()),
+		Score:        score.Score,
+		Accuracy:     score.Accuracy,
+		Grade:        score.Grade.String(),
+		MaxCombo:     int64(score.Combo),
+		PerfectCombo: score.PerfectCombo,
+		Count300:     int64(score.Count300),
+		CountGeki:    int64(score.CountGeki),
+		Count100:     int64(score.Count100),
+		CountKatu:    int64(score.CountKatu),
+		Count50:      int64(score.Count50),
+		CountMiss:    int64(score.CountMiss),
+		SliderBreak:  int64(score.CountSB),
+		PP:           score.PP.Total,
+		PPVersion:    performance.GetVersion(settings.Gameplay.PPVersion).Name,
+		UR:           overlay.hitErrorMeter.GetUnstableRateConverted(),
+		Date:         overlay.cursor.ScoreTime,
+		Replay:       overlay.cursor.ReplayPath,
+	}
+
+	goroutines.Run(func() {
+		if err := database.SaveScore(dbScore); err != nil {
+			log.Println("Failed to save the score:", err)
+			return
+		}
+
+		log.Println("Score saved to the database")
+	})
 }
 
 func (overlay *ScoreOverlay) updateNormal(time float64) {
@@ -284,6 +284,18 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 		} else {
 			player.overlay = overlays.NewKnockoutOverlay(controller.(*dance.ReplayController))
 		}
+	} else if settings.CursorDance.Battle {
+		controller := dance.NewBattleController()
+		player.controller = controller
+
+		player.controller.SetBeatMap(player.bMap)
+		player.controller.InitCursors()
+
+		if settings.PLAYERS == 1 {
+			player.overlay = overlays.NewScoreOverlay(controller.(*dance.BattleController).GetRuleset(), player.controller.GetCursors()[0])
+		} else {
+			player.overlay = overlays.NewKnockoutOverlay(controller.(*dance.BattleController))
+		}
 	} else {
 		player.controller = dance.NewGenericController()
 		player.controller.SetBeatMap(player.bMap)
@@ -685,6 +697,10 @@ func (player *Player) GetTimeOffset() float64 {
 	return player.progressMsF - player.startOffset
 }
 
+func (player *Player) GetRunningTime() float64 {
+	return player.RunningTime
+}
+
 func (player *Player) updateMain(delta float64) {
 	player.realTime += delta
 
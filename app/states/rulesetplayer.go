@@ -0,0 +1,441 @@
+package states
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/bmath"
+	camera2 "github.com/wieku/danser-go/app/bmath/camera"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/app/discord"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/states/components/common"
+	"github.com/wieku/danser-go/app/states/components/containers"
+	"github.com/wieku/danser-go/framework/bass"
+	"github.com/wieku/danser-go/framework/frame"
+	"github.com/wieku/danser-go/framework/goroutines"
+	batch2 "github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/math/animation"
+	"github.com/wieku/danser-go/framework/math/animation/easing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/qpc"
+	"github.com/wieku/rplpa"
+	"log"
+	"runtime"
+)
+
+const scaledHeight = 1080.0
+
+// rulesetPlayfield draws and drives a ruleset other than osu!standard
+type rulesetPlayfield interface {
+	Update(time float64)
+	Draw(batch *batch2.QuadBatch, camera mgl32.Mat4, alpha float64)
+	IsEnded() bool
+}
+
+// RulesetPlayer plays back replays of rulesets other than osu!standard
+type RulesetPlayer struct {
+	bMap   *beatmap.BeatMap
+	replay *rplpa.Replay
+
+	batch       *batch2.QuadBatch
+	background  *common.Background
+	playfield   rulesetPlayfield
+	musicPlayer bass.ITrack
+
+	exportScore func() *database.Score
+
+	bgCamera *camera2.Camera
+	uiCamera *camera2.Camera
+
+	dimGlider    *bmath.DimGlider
+	blurGlider   *bmath.DimGlider
+	hudGlider    *animation.Glider
+	volumeGlider *animation.Glider
+
+	lastMusicPos float64
+	rawPositionF float64
+	progressMsF  float64
+
+	start      bool
+	startPoint float64
+
+	startOffset float64
+	mapEndL     float64
+	MapEnd      float64
+	RunningTime float64
+
+	scoreSaved bool
+}
+
+func NewManiaPlayer(beatMap *beatmap.BeatMap, replay *rplpa.Replay) *RulesetPlayer {
+	ruleset, err := mania.NewManiaRuleSet(beatMap, beatMap.Diff, replay)
+	if err != nil {
+		panic(err)
+	}
+
+	notes := ruleset.GetNotes()
+	if len(notes) == 0 {
+		panic("Beatmap has no notes!")
+	}
+
+	endTime := 0.0
+	for _, n := range notes {
+		endTime = max(endTime, n.EndTime)
+	}
+
+	playfield := containers.NewManiaPlayfield(ruleset, scaledWidth(), scaledHeight)
+
+	return newRulesetPlayer(beatMap, replay, playfield, notes[0].StartTime-settings.Gameplay.Mania.ScrollTime, endTime, func() *database.Score {
+		score := ruleset.GetScore()
+
+		return &database.Score{
+			Score:     score.Score,
+			Accuracy:  score.Accuracy,
+			Grade:     score.Grade.String(),
+			MaxCombo:  int64(score.Combo),
+			Count300:  int64(score.Count300),
+			CountGeki: int64(score.CountMax),
+			Count100:  int64(score.Count100),
+			CountKatu: int64(score.Count200),
+			Count50:   int64(score.Count50),
+			CountMiss: int64(score.CountMiss),
+			UR:        ruleset.GetUnstableRate(),
+		}
+	})
+}
+
+func NewTaikoPlayer(beatMap *beatmap.BeatMap, replay *rplpa.Replay) *RulesetPlayer {
+	ruleset, err := taiko.NewTaikoRuleSet(beatMap, beatMap.Diff, replay)
+	if err != nil {
+		panic(err)
+	}
+
+	taikoObjects := ruleset.GetObjects()
+	if len(taikoObjects) == 0 {
+		panic("Beatmap has no objects!")
+	}
+
+	endTime := 0.0
+	for _, o := range taikoObjects {
+		endTime = max(endTime, o.EndTime)
+	}
+
+	playfield := containers.NewTaikoPlayfield(ruleset, scaledWidth(), scaledHeight)
+
+	return newRulesetPlayer(beatMap, replay, playfield, taikoObjects[0].StartTime-1000, endTime, func() *database.Score {
+		score := ruleset.GetScore()
+
+		return &database.Score{
+			Score:     score.Score,
+			Accuracy:  score.Accuracy,
+			Grade:     score.Grade.String(),
+			MaxCombo:  int64(score.Combo),
+			Count300:  int64(score.CountGreat),
+			Count100:  int64(score.CountOk),
+			CountMiss: int64(score.CountMiss),
+			UR:        ruleset.GetUnstableRate(),
+		}
+	})
+}
+
+func scaledWidth() float64 {
+	return scaledHeight * settings.Graphics.GetAspectRatio()
+}
+
+// newRulesetPlayer sets up music and timeline of the replay, beatmapStart is the time when the first object appears and endTime is the end of the last object
+func newRulesetPlayer(beatMap *beatmap.BeatMap, replay *rplpa.Replay, playfield rulesetPlayfield, beatmapStart, endTime float64, exportScore func() *database.Score) *RulesetPlayer {
+	player := new(RulesetPlayer)
+
+	graphics.LoadTextures()
+
+	if settings.Graphics.Experimental.UsePersistentBuffers {
+		player.batch = batch2.NewQuadBatchPersistent()
+	} else {
+		player.batch = batch2.NewQuadBatch()
+	}
+
+	discord.SetMap(beatMap.Artist, beatMap.Name, beatMap.Difficulty)
+
+	player.bMap = beatMap
+	player.replay = replay
+	player.playfield = playfield
+	player.exportScore = exportScore
+
+	log.Println("Playing:", fmt.Sprintf("%s - %s [%s]", beatMap.Artist, beatMap.Name, beatMap.Difficulty))
+
+	var track *bass.TrackBass
+	if fPath, err2 := beatMap.GetAudioFile(); err2 == nil {
+		track = bass.NewTrack(fPath)
+	}
+
+	if track == nil {
+		log.Println("Failed to create music stream, creating a dummy stream...")
+
+		player.musicPlayer = bass.NewTrackVirtual(endTime/1000 + 1)
+	} else {
+		log.Println("Audio track:", beatMap.Audio)
+
+		player.musicPlayer = track
+	}
+
+	player.background = common.NewBackground(true)
+	player.background.SetBeatmap(beatMap, true, true)
+	player.background.SetTrack(player.musicPlayer)
+
+	player.bgCamera = camera2.NewCamera()
+	player.bgCamera.SetOsuViewport(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()), 1, false, false)
+	player.bgCamera.Update()
+
+	player.uiCamera = camera2.NewCamera()
+	player.uiCamera.SetViewport(int(scaledWidth()), int(scaledHeight), true)
+	player.uiCamera.SetViewportF(0, int(scaledHeight), int(scaledWidth()), 0)
+	player.uiCamera.Update()
+
+	player.dimGlider = bmath.NewDimGlider(0)
+	player.dimGlider.SetEasing(easing.OutQuad)
+
+	player.blurGlider = bmath.NewDimGlider(0)
+	player.blurGlider.SetEasing(easing.OutQuad)
+
+	player.hudGlider = animation.NewGlider(0)
+	player.hudGlider.SetEasing(easing.OutQuad)
+
+	player.volumeGlider = animation.NewGlider(1)
+
+	beatmapEnd := endTime + 1000
+
+	if settings.SKIP && beatmapStart > 0.01 {
+		player.startPoint = beatmapStart
+
+		player.volumeGlider.SetValue(0.0)
+		player.volumeGlider.AddEvent(beatmapStart, beatmapStart+beatMap.Diff.TimeFadeIn, 1.0)
+	}
+
+	startOffset := min(beatmapStart, player.startPoint)
+
+	startOffset += -settings.Playfield.LeadInHold * 1000
+
+	player.dimGlider.AddEvent(startOffset-500, startOffset, bmath.Intro)
+	player.blurGlider.AddEvent(startOffset-500, startOffset, bmath.Intro)
+	player.hudGlider.AddEvent(startOffset-500, startOffset, 1.0)
+
+	player.dimGlider.AddEvent(beatmapStart, beatmapStart+1000, bmath.Normal)
+	player.blurGlider.AddEvent(beatmapStart, beatmapStart+1000, bmath.Normal)
+
+	fadeOut := settings.Playfield.FadeOutTime * 1000
+	endingPad := settings.Playfield.EndingPad * 1000
+
+	player.hudGlider.AddEvent(beatmapEnd+endingPad, beatmapEnd+endingPad+fadeOut, 0.0)
+	player.dimGlider.AddEventV(beatmapEnd+endingPad, beatmapEnd+endingPad+fadeOut, 0.0, bmath.Absolute)
+	player.volumeGlider.AddEvent(beatmapEnd+endingPad, beatmapEnd+endingPad+fadeOut, 0.0)
+
+	for _, p := range beatMap.Pauses {
+		if p.GetEndTime()-p.GetStartTime() < 1000 {
+			continue
+		}
+
+		player.dimGlider.AddEvent(p.GetStartTime(), p.GetStartTime()+1000, bmath.Break)
+		player.blurGlider.AddEvent(p.GetStartTime(), p.GetStartTime()+1000, bmath.Break)
+		player.dimGlider.AddEvent(p.GetEndTime(), p.GetEndTime()+1000, bmath.Normal)
+		player.blurGlider.AddEvent(p.GetEndTime(), p.GetEndTime()+1000, bmath.Normal)
+	}
+
+	player.mapEndL = beatmapEnd + fadeOut
+	player.MapEnd = beatmapEnd + endingPad + fadeOut + 100
+
+	player.musicPlayer.AddSilence(max(0, player.MapEnd/1000-player.musicPlayer.GetLength()))
+
+	startOffset -= max(settings.Playfield.LeadInTime*1000, 1000)
+
+	player.startOffset = startOffset
+	player.progressMsF = startOffset
+	player.rawPositionF = startOffset
+
+	player.RunningTime = player.MapEnd - startOffset
+
+	player.background.Update(player.progressMsF, settings.Graphics.GetWidthF()/2, settings.Graphics.GetHeightF()/2)
+
+	if settings.RECORD {
+		return player
+	}
+
+	updateLimiter := frame.NewLimiter(1000)
+
+	goroutines.RunOS(func() {
+		var lastTimeNano = qpc.GetNanoTime()
+
+		for !input.Win.ShouldClose() {
+			currentTimeNano := qpc.GetNanoTime()
+
+			delta := float64(currentTimeNano-lastTimeNano) / 1000000.0
+
+			musicState := player.musicPlayer.GetState()
+
+			speed := 1.0
+
+			if musicState == bass.MusicStopped {
+				if player.rawPositionF < player.startPoint || player.start {
+					player.rawPositionF += delta
+				} else {
+					speed = settings.SPEED * player.bMap.Diff.GetSpeed()
+					player.rawPositionF += delta * speed
+				}
+			} else {
+				musicPos := player.musicPlayer.GetPosition() * 1000
+				speed = player.musicPlayer.GetSpeed()
+
+				if musicPos != player.lastMusicPos || musicState == bass.MusicPaused {
+					player.rawPositionF = musicPos
+					player.lastMusicPos = musicPos
+				} else if musicPos > 1 {
+					player.rawPositionF += delta * speed
+				}
+			}
+
+			platformOffset := 0.0
+			if runtime.GOOS == "windows" {
+				platformOffset = windowsOffset
+			}
+
+			player.progressMsF = player.rawPositionF + (platformOffset+float64(settings.Audio.Offset))*speed - float64(settings.LOCALOFFSET)
+
+			player.updateMain()
+
+			lastTimeNano = currentTimeNano
+
+			updateLimiter.Sync()
+		}
+
+		player.musicPlayer.Stop()
+		bass.StopLoops()
+	})
+
+	return player
+}
+
+func (player *RulesetPlayer) Update(delta float64) bool {
+	speed := 1.0
+
+	if player.musicPlayer.GetState() == bass.MusicPlaying {
+		speed = player.musicPlayer.GetSpeed()
+	} else if !(player.progressMsF < player.startPoint || player.start) {
+		speed = settings.SPEED * player.bMap.Diff.GetSpeed()
+	}
+
+	player.rawPositionF += delta * speed
+
+	player.progressMsF = player.rawPositionF - float64(settings.LOCALOFFSET)
+
+	player.updateMain()
+
+	if player.progressMsF >= player.MapEnd {
+		player.musicPlayer.Stop()
+		bass.StopLoops()
+
+		return true
+	}
+
+	return false
+}
+
+func (player *RulesetPlayer) GetTime() float64 {
+	return player.progressMsF
+}
+
+func (player *RulesetPlayer) GetTimeOffset() float64 {
+	return player.progressMsF - player.startOffset
+}
+
+func (player *RulesetPlayer) GetRunningTime() float64 {
+	return player.RunningTime
+}
+
+func (player *RulesetPlayer) updateMain() {
+	if player.rawPositionF >= player.startPoint && !player.start {
+		player.musicPlayer.Play()
+		player.musicPlayer.SetPosition(player.startPoint / 1000)
+
+		discord.SetDuration(int64((player.mapEndL - player.musicPlayer.GetPosition()*1000) / (settings.SPEED * player.bMap.Diff.GetSpeed())))
+
+		player.start = true
+	}
+
+	freqAdjust := 1.0
+	speedAdjust := settings.SPEED
+
+	if player.bMap.Diff.AdjustsPitch() {
+		freqAdjust = player.bMap.Diff.GetSpeed()
+	} else {
+		speedAdjust *= player.bMap.Diff.GetSpeed()
+	}
+
+	player.musicPlayer.SetTempo(speedAdjust)
+	player.musicPlayer.SetPitch(settings.PITCH)
+	player.musicPlayer.SetRelativeFrequency(freqAdjust)
+	player.musicPlayer.Update()
+
+	if player.progressMsF < player.mapEndL {
+		player.playfield.Update(player.progressMsF)
+	}
+
+	if player.playfield.IsEnded() && !player.scoreSaved {
+		player.scoreSaved = true
+		player.saveScore()
+	}
+
+	player.background.Update(player.progressMsF, 0, 0)
+
+	bgDim := settings.Playfield.Background.Dim
+	blurDim := settings.Playfield.Background.Blur.Values
+
+	player.dimGlider.Update(player.progressMsF, 1-bgDim.Intro, 1-bgDim.Normal, 1-bgDim.Breaks)
+	player.blurGlider.Update(player.progressMsF, blurDim.Intro, blurDim.Normal, blurDim.Breaks)
+	player.hudGlider.Update(player.progressMsF)
+	player.volumeGlider.Update(player.progressMsF)
+
+	if player.musicPlayer.GetState() == bass.MusicPlaying {
+		player.musicPlayer.SetVolumeRelative(player.volumeGlider.GetValue())
+	}
+}
+
+func (player *RulesetPlayer) saveScore() {
+	if player.replay == nil || !settings.Gameplay.SaveReplayScores {
+		return
+	}
+
+	dbScore := player.exportScore()
+	dbScore.BeatmapMD5 = player.bMap.MD5
+	dbScore.Player = player.replay.Username
+	dbScore.Source = database.SourceReplay
+	dbScore.Mods = player.bMap.Diff.GetModString()
+	dbScore.LegacyMods = int64(player.bMap.Diff.Mods.Legacy())
+	dbScore.Date = player.replay.Timestamp
+	dbScore.Replay = settings.REPLAY
+
+	goroutines.Run(func() {
+		if err := database.SaveScore(dbScore); err != nil {
+			log.Println("Failed to save the score:", err)
+		}
+	})
+}
+
+func (player *RulesetPlayer) Draw(float64) {
+	bgAlpha := player.dimGlider.GetValue()
+
+	player.background.Draw(player.progressMsF, player.batch, player.blurGlider.GetValue(), bgAlpha, player.bgCamera.GetProjectionView())
+
+	player.playfield.Draw(player.batch, player.uiCamera.GetProjectionView(), mutils.Clamp(player.hudGlider.GetValue(), 0, 1))
+
+	player.background.DrawOverlay(player.progressMsF, player.batch, bgAlpha, player.bgCamera.GetProjectionView())
+}
+
+func (player *RulesetPlayer) Show() {}
+
+func (player *RulesetPlayer) Hide() {}
+
+func (player *RulesetPlayer) Dispose() {}
@@ -6,3 +6,12 @@ type State interface {
 	Draw(delta float64)
 	Dispose()
 }
+
+// PlaybackState is a state that can be driven manually, e.g. when recording
+type PlaybackState interface {
+	State
+	Update(delta float64) bool
+	GetTime() float64
+	GetTimeOffset() float64
+	GetRunningTime() float64
+}
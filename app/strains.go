@@ -0,0 +1,32 @@
+package app
+
+import (
+	"github.com/wieku/danser-go/app/analysis"
+	"github.com/wieku/danser-go/app/beatmap"
+	"log"
+	"path/filepath"
+)
+
+func runStrains(beatMap *beatmap.BeatMap) {
+	log.Println("Calculating per-object strains...")
+
+	strains, err := analysis.CalculateStrains(beatMap)
+	if err != nil {
+		panic(err)
+	}
+
+	path := analysis.GetDefaultStrainsPath(beatMap)
+	if output != "" {
+		path = output
+
+		if filepath.Ext(path) == "" {
+			path += ".csv"
+		}
+	}
+
+	if err = analysis.SaveStrains(strains, path); err != nil {
+		panic("Failed to save strains: " + err.Error())
+	}
+
+	log.Println("Strains saved to:", path)
+}
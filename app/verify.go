@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/wieku/danser-go/app/analysis"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runVerification(beatMap *beatmap.BeatMap) {
+	data, err := os.ReadFile(settings.REPLAY)
+	if err != nil {
+		panic(err)
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		panic(err)
+	}
+
+	log.Println("Verifying replay...")
+
+	report := analysis.AnalyzeReplay(beatMap)
+
+	verification := analysis.VerifyReplay(beatMap, replay, report)
+
+	if verification.Matches {
+		log.Println("Replay verified, danser's results match the recorded ones")
+	} else {
+		log.Println("Replay verification failed, danser's results differ from the recorded ones:")
+
+		tableString := &strings.Builder{}
+		table := tablewriter.NewWriter(tableString)
+		table.SetHeader([]string{"Field", "Recorded", "danser", "Difference"})
+
+		for _, m := range verification.Mismatches {
+			table.Append([]string{m.Field, fmt.Sprintf("%d", m.Recorded), fmt.Sprintf("%d", m.Computed), fmt.Sprintf("%+d", m.Computed-m.Recorded)})
+		}
+
+		table.Render()
+
+		for _, s := range strings.Split(tableString.String(), "\n") {
+			log.Println(s)
+		}
+
+		log.Println("Objects where judgements could have diverged:")
+
+		for _, s := range verification.Suspicious {
+			log.Println(fmt.Sprintf("\t#%d at %dms (%s): %s", s.Object, s.Time, s.Result, s.Reason))
+		}
+	}
+
+	if lifeBar := verification.LifeBar; lifeBar != nil {
+		log.Println(fmt.Sprintf("Life bar: max HP deviation of %.2f%% at %dms", lifeBar.MaxDeviation*100, lifeBar.MaxDeviationTime))
+
+		if lifeBar.Drifted() {
+			log.Println(fmt.Sprintf("Life bar: danser's HP starts to drift at %dms", lifeBar.DriftStart))
+		}
+
+		if lifeBar.RecordedFailed != lifeBar.ComputedFailed {
+			log.Println(fmt.Sprintf("Life bar: recorded HP reached zero: %t, danser failed: %t", lifeBar.RecordedFailed, lifeBar.ComputedFailed))
+		}
+	}
+
+	path := strings.TrimSuffix(settings.REPLAY, filepath.Ext(settings.REPLAY)) + ".verify.json"
+	if output != "" {
+		path = strings.TrimSuffix(output, filepath.Ext(output)) + ".json"
+	}
+
+	if err = verification.Save(path); err != nil {
+		panic("Failed to save the verification: " + err.Error())
+	}
+
+	log.Println("Verification saved to:", path)
+}
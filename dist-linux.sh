@@ -19,7 +19,7 @@ mkdir -p $BUILD_DIR
 
 go run tools/assets/assets.go ./ $BUILD_DIR/
 
-go build -trimpath -ldflags "-s -w -X 'github.com/wieku/danser-go/build.VERSION=$build' -X 'github.com/wieku/danser-go/build.Stream=Release'" -buildmode=c-shared -o $BUILD_DIR/danser-core.so -v -x -tags "exclude_cimgui_glfw exclude_cimgui_sdli"
+go build -trimpath -ldflags "-s -w -X 'github.com/wieku/danser-go/build.VERSION=$build' -X 'github.com/wieku/danser-go/build.Stream=Release'" -buildmode=c-shared -o $BUILD_DIR/danser-core.so -v -x -tags "exclude_cimgui_glfw exclude_cimgui_sdli sqlite_fts5"
 
 mv $BUILD_DIR/danser-core.so $BUILD_DIR/libdanser-core.so
 cp {libbass.so,libbass_fx.so,libbassmix.so,libyuv.so} $BUILD_DIR/
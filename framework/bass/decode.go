@@ -0,0 +1,41 @@
+package bass
+
+/*
+#include <stdlib.h>
+#include "bass.h"
+*/
+import "C"
+
+import (
+	"runtime"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// CanDecode checks whether BASS is able to open the file and decode its beginning. BASS has to be initialized.
+func CanDecode(path string) bool {
+	var channel C.HSTREAM
+
+	if runtime.GOOS == "windows" {
+		wFile := utf16.Encode([]rune(path))
+		wFile = append(wFile, 0) // NULL terminated string
+
+		channel = C.BASS_StreamCreateFile(0, unsafe.Pointer(&wFile[0]), 0, 0, C.DWORD(C.BASS_STREAM_DECODE|C.BASS_UNICODE))
+	} else {
+		cPath := C.CString(path)
+		defer C.free(unsafe.Pointer(cPath))
+
+		channel = C.BASS_StreamCreateFile(0, unsafe.Pointer(cPath), 0, 0, C.DWORD(C.BASS_STREAM_DECODE))
+	}
+
+	if channel == 0 {
+		return false
+	}
+
+	defer C.BASS_StreamFree(channel)
+
+	// Some files have a valid header but data can't be decoded
+	buffer := make([]byte, 4096)
+
+	return C.BASS_ChannelGetData(channel, unsafe.Pointer(&buffer[0]), C.DWORD(len(buffer))) != C.DWORD(0xFFFFFFFF)
+}
@@ -20,6 +20,13 @@ var masterMixer C.HSTREAM
 
 var sampleRate = 44100
 
+var initialized bool
+
+// Initialized returns true if BASS was successfully initialized
+func Initialized() bool {
+	return initialized
+}
+
 func Init(offscreen bool) {
 	log.Println("Initializing BASS...")
 
@@ -66,6 +73,8 @@ func Init(offscreen bool) {
 	}
 
 	if C.BASS_Init(C.int(deviceId), C.DWORD(sampleRate), C.DWORD(0), nil, nil) != 0 {
+		initialized = true
+
 		log.Println("BASS Initialized!")
 		log.Println("BASS Version:       ", parseVersion(int(C.BASS_GetVersion())))
 		log.Println("BASS FX Version:    ", parseVersion(int(C.BASS_FX_GetVersion())))
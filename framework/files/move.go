@@ -3,7 +3,9 @@ package files
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 )
 
 // MoveFile allows moving the file across drives compared to os.Rename
@@ -34,3 +36,40 @@ func MoveFile(source, destination string) error {
 
 	return nil
 }
+
+// MoveDir moves the directory with its contents, falls back to moving files one by one if os.Rename fails (e.g. across drives)
+func MoveDir(source, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(source, destination); err == nil {
+		return nil
+	}
+
+	err := filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(destination, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		return MoveFile(path, target)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Only empty directories should be left
+	return os.RemoveAll(source)
+}
@@ -23,6 +23,8 @@ func StartLogging(logName string) {
 		panic(err)
 	}
 
+	logFile = file
+
 	log.SetOutput(file)
 
 	PrintPlatformInfo()
@@ -30,6 +32,18 @@ func StartLogging(logName string) {
 	log.SetOutput(io.MultiWriter(os.Stdout, file))
 }
 
+var logFile *os.File
+
+// SetConsoleOutput changes where logs are printed besides the log file, e.g. to keep stdout clean for machine-readable output
+func SetConsoleOutput(w io.Writer) {
+	if logFile == nil {
+		log.SetOutput(w)
+		return
+	}
+
+	log.SetOutput(io.MultiWriter(w, logFile))
+}
+
 func PrintPlatformInfo() {
 	osName, cpuName, ramAmount := "Unknown", "Unknown", "Unknown"
 
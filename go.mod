@@ -21,6 +21,7 @@ require (
 	github.com/spf13/cast v1.7.1
 	github.com/sqweek/dialog v0.0.0-20220504154117-be45b268883a
 	github.com/wieku/rplpa v1.0.2
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394
 	golang.org/x/image v0.25.0
 	golang.org/x/oauth2 v0.28.0
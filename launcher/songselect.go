@@ -2,9 +2,11 @@ package launcher
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/database"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/framework/bass"
 	"github.com/wieku/danser-go/framework/graphics/texture"
@@ -13,6 +15,7 @@ import (
 	"github.com/wieku/danser-go/framework/platform"
 	"github.com/wieku/danser-go/framework/qpc"
 	"github.com/wieku/danser-go/framework/util"
+	"log"
 	"math"
 	"math/rand"
 	"path/filepath"
@@ -90,6 +93,11 @@ type songSelectPopup struct {
 
 	searchStr string
 
+	collections       []*database.Collection
+	collection        *database.Collection
+	newCollectionName string
+	collectionsDirty  bool
+
 	prevMap       *beatmap.BeatMap
 	PreviewedSong *bass.TrackBass
 	volume        *animation.Glider
@@ -115,6 +123,8 @@ func newSongSelectPopup(bld *builder, beatmaps []*beatmap.BeatMap) *songSelectPo
 
 	mP.internalDraw = mP.drawSongSelect
 
+	mP.collections = database.LoadCollections()
+
 	mP.setBeatmaps(beatmaps)
 
 	return mP
@@ -146,6 +156,14 @@ func (m *songSelectPopup) update() {
 }
 
 func (m *songSelectPopup) drawSongSelect() {
+	if m.collectionsDirty {
+		m.collectionsDirty = false
+
+		if m.collection != nil {
+			m.search()
+		}
+	}
+
 	imgui.PushFont(Font32)
 
 	imgui.SetNextItemWidth(-1)
@@ -212,8 +230,65 @@ func (m *songSelectPopup) drawSongSelect() {
 		ImIO.SetFontGlobalScale(1)
 		imgui.PopFont()
 
+		imgui.SameLine()
+
+		imgui.TextUnformatted("Collection:")
+
+		imgui.SameLine()
+
+		cName := "All"
+		if m.collection != nil {
+			cName = m.collection.Name
+		}
+
+		imgui.SetNextItemWidth(200)
+
+		if imgui.BeginCombo("##collectioncombo", cName) {
+			m.comboOpened = true
+
+			if imgui.SelectableBoolV("All", m.collection == nil, 0, vzero()) && m.collection != nil {
+				m.collection = nil
+				m.search()
+				m.focusTheMap = true
+			}
+
+			for i, c := range m.collections {
+				if imgui.SelectableBoolV(fmt.Sprintf("%s (%d)##col%d", c.Name, len(c.MD5s), i), c == m.collection, 0, vzero()) && c != m.collection {
+					m.collection = c
+					m.search()
+					m.focusTheMap = true
+				}
+			}
+
+			imgui.EndCombo()
+		}
+
 		imgui.TableNextColumn()
 
+		if len(m.collections) == 0 {
+			imgui.BeginDisabled()
+		}
+
+		if imgui.Button("Export collections") {
+			if err := database.ExportCollections(m.collections); err != nil {
+				showMessage(mError, "Failed to export collections!\nError: %s", err)
+			} else {
+				showMessage(mInfo, "Collections exported to:\n%s\n\nFile from before the first export is kept as collection.db.bak", database.OsuCollectionsPath())
+			}
+		}
+
+		if len(m.collections) == 0 {
+			imgui.EndDisabled()
+		}
+
+		if imgui.IsItemHoveredV(imgui.HoveredFlagsAllowWhenDisabled) {
+			imgui.BeginTooltip()
+			imgui.TextUnformatted("Overwrites osu!'s collection.db with collections visible in danser.\nosu! has to be closed, otherwise it will overwrite the file on exit.")
+			imgui.EndTooltip()
+		}
+
+		imgui.SameLine()
+
 		if imgui.Button("Random") {
 			m.selectRandom()
 		}
@@ -424,7 +499,11 @@ func (m *songSelectPopup) drawSongSelect() {
 					m.opened = false
 				}
 
-				if imgui.IsItemHovered() && ImIO.MousePos().X <= sPos.X+tSiz.X {
+				if imgui.BeginPopupContextItem() {
+					m.drawCollectionMenu(bMap)
+
+					imgui.EndPopup()
+				} else if imgui.IsItemHovered() && ImIO.MousePos().X <= sPos.X+tSiz.X {
 					m.showMapTooltip(bMap)
 				}
 			}
@@ -459,6 +538,52 @@ func (m *songSelectPopup) drawSongSelect() {
 	imgui.WindowDrawList().AddLine(csPos, csPos.Add(vec2(imgui.ContentRegionAvail().X, 0)), packColor(*imgui.StyleColorVec4(imgui.ColSeparator)))
 }
 
+// drawCollectionMenu draws context menu that adds the map to collections or removes it from them
+func (m *songSelectPopup) drawCollectionMenu(bMap *beatmap.BeatMap) {
+	m.comboOpened = true // Don't steal keyboard focus from the name input
+
+	imgui.TextUnformatted("Collections")
+	imgui.Separator()
+
+	for i, c := range m.collections {
+		if imgui.MenuItemBoolV(c.Name+"##colmenu"+strconv.Itoa(i), "", c.Contains(bMap.MD5), true) {
+			c.Toggle(bMap.MD5)
+			m.saveCollections()
+		}
+	}
+
+	if len(m.collections) > 0 {
+		imgui.Separator()
+	}
+
+	imgui.SetNextItemWidth(250)
+
+	if imgui.InputTextWithHint("##newcollection", "New collection", &m.newCollectionName, imgui.InputTextFlagsEnterReturnsTrue, nil) {
+		name := strings.TrimSpace(m.newCollectionName)
+
+		if name != "" && database.FindCollection(m.collections, name) == nil {
+			collection := database.NewCollection(name)
+			collection.Toggle(bMap.MD5)
+
+			m.collections = append(m.collections, collection)
+
+			m.saveCollections()
+		}
+
+		m.newCollectionName = ""
+
+		imgui.CloseCurrentPopup()
+	}
+}
+
+func (m *songSelectPopup) saveCollections() {
+	if err := database.SaveCollections(m.collections); err != nil {
+		showMessage(mError, "Failed to save collections!\nError: %s", err)
+	}
+
+	m.collectionsDirty = true
+}
+
 func (m *songSelectPopup) showMapTooltip(bMap *beatmap.BeatMap) {
 	imgui.PushFont(Font24)
 
@@ -642,10 +767,42 @@ func (m *songSelectPopup) search() {
 
 	sString := strings.ToLower(m.searchStr)
 
+	var inCollection map[string]bool
+
+	if m.collection != nil {
+		inCollection = make(map[string]bool, len(m.collection.MD5s))
+
+		for _, md5 := range m.collection.MD5s {
+			inCollection[strings.ToLower(md5)] = true
+		}
+	}
+
+	// Full-text index also matches unicode titles, source, tags and ignores diacritics
+	var ftsHits map[string]bool
+
+	if sString != "" && database.SearchAvailable() {
+		results, err := database.SearchBeatmaps(sString, 0)
+		if err != nil {
+			if !errors.Is(err, database.ErrNoSearchWords) {
+				log.Println("Full-text search failed:", err)
+			}
+		} else {
+			ftsHits = make(map[string]bool, len(results))
+
+			for _, r := range results {
+				ftsHits[filepath.Join(r.Dir, r.File)] = true
+			}
+		}
+	}
+
 	foundMaps := make([]*beatmap.BeatMap, 0, len(m.beatmaps))
 
 	for _, b := range m.beatmaps {
-		if sString != "" && !strings.Contains(b.name, sString) {
+		if sString != "" && !strings.Contains(b.name, sString) && !ftsHits[filepath.Join(b.bMap.Dir, b.bMap.File)] {
+			continue
+		}
+
+		if inCollection != nil && !inCollection[strings.ToLower(b.bMap.MD5)] {
 			continue
 		}
 